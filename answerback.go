@@ -0,0 +1,127 @@
+/*
+ * Answerback handling. Receiving the WRU(Who Are You) control, ITA2 figures code 9, makes a teleprinter
+ * send the answerback text set on its drum, at most 20 characters.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// WRU is the Who Are You control as it's decoded from figures code 9 of ITA2
+	WRU rune = '\u0005'
+	// maximum number of characters on an answerback drum
+	maxAnswerbackLen = 20
+)
+
+type answerback struct {
+	ver   version
+	text  string
+	codes []byte
+	wru   []byte
+
+	// register of the incoming stream
+	charset Charset
+}
+
+// NewAnswerback creates the answerback unit of a station, the codec's variant must have the WRU control
+func NewAnswerback(codec Codec, text string) (*answerback, error) {
+	v, ok := codec.(versioned)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported codec: %T", codec)
+	}
+	if len([]rune(text)) > maxAnswerbackLen {
+		return nil, fmt.Errorf("Answerback longer than %d characters: %s", maxAnswerbackLen, text)
+	}
+
+	ls, err := shiftCode(v.ver(), Letters)
+	if err != nil {
+		return nil, err
+	}
+
+	wruCode, wruCharset, err := encodeChar(WRU, Letters, v.ver())
+	if err != nil {
+		return nil, fmt.Errorf("Variant has no WRU control")
+	}
+	wruShift, err := shiftCode(v.ver(), wruCharset)
+	if err != nil {
+		return nil, err
+	}
+
+	// the drum starts with LS so the answerback doesn't depend on the register of the remote station
+	codes := []byte{ls}
+	currentCharset := Letters
+	for _, char := range text {
+		code, shiftedCharset, err := encodeChar(char, currentCharset, v.ver())
+		if err != nil {
+			return nil, err
+		}
+		if shiftedCharset != currentCharset {
+			shifters, err := shiftPath(v.ver(), currentCharset, shiftedCharset)
+			if err != nil {
+				return nil, err
+			}
+			currentCharset = shiftedCharset
+			codes = append(codes, shifters...)
+		}
+		codes = append(codes, code)
+	}
+
+	return &answerback{
+		ver:   v.ver(),
+		text:  text,
+		codes: codes,
+		wru:   []byte{wruShift, wruCode},
+	}, nil
+}
+
+// Text returns the answerback text
+func (a *answerback) Text() string {
+	return a.text
+}
+
+// Codes returns the encoded answerback sent in response to WRU
+func (a *answerback) Codes() []byte {
+	return append([]byte{}, a.codes...)
+}
+
+// WRU returns the codes asking the remote station for its answerback
+func (a *answerback) WRU() []byte {
+	return append([]byte{}, a.wru...)
+}
+
+// Receive scans an incoming stream for WRU, the register is kept between calls.
+// Returns the answerback codes to send, once per WRU found, and whether any WRU was found
+func (a *answerback) Receive(codes []byte) ([]byte, bool) {
+	var response []byte
+	for _, code := range codes {
+		ch, shiftedCharset, err := decodeChar(code, a.charset, a.ver)
+		if err != nil {
+			continue
+		}
+		if shiftedCharset != a.charset {
+			a.charset = shiftedCharset
+			continue
+		}
+		if ch == WRU {
+			response = append(response, a.codes...)
+		}
+	}
+
+	return response, len(response) > 0
+}
+
+// MatchAnswerback reports whether a received answerback matches the expected pattern(a regular expression),
+// the surrounding CR, LF and spaces sent by the drum are ignored
+func MatchAnswerback(pattern string, received string) (bool, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(strings.Trim(received, " \r\n\u0000")), nil
+}
@@ -0,0 +1,119 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewAnswerback(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		codec      Codec
+		text       string
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test valid answerback",
+			codec:      NewITA2(false),
+			text:       "\r\n12345 BAUDOT D",
+			failedText: "expect no error, got %v",
+		},
+		{
+			caseName:   "test answerback too long",
+			codec:      NewITA2(false),
+			text:       "\r\n12345 BAUDOT GERMANY",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test invalid char",
+			codec:      NewITA2(false),
+			text:       "12345 $",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test variant without WRU",
+			codec:      NewUSTTY(false),
+			text:       "12345 BAUDOT",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			a, err := NewAnswerback(tc.codec, tc.text)
+			if (err != nil) != tc.shouldFail {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", a, err))
+			}
+		})
+	}
+}
+
+func TestAnswerbackReceive(t *testing.T) {
+	c := NewITA2(false)
+	a, _ := NewAnswerback(c, "\r\n12345 BAUDOT D")
+
+	// the WRU request arrives split between calls, the register is kept
+	request := append([]byte{LS, 1, 2}, a.WRU()...)
+	if response, ok := a.Receive(request[:3]); ok || len(response) != 0 {
+		t.Errorf("expect no response, got %v", response)
+	}
+	if response, ok := a.Receive(request[3:4]); ok || len(response) != 0 {
+		t.Errorf("expect no response, got %v", response)
+	}
+	response, ok := a.Receive(request[4:])
+	if !ok {
+		t.Fatalf("expect a response")
+	}
+
+	str, err := c.Decode(response)
+	if err != nil || str != "\r\n12345 BAUDOT D" {
+		t.Errorf("expect the answerback, got %q, %v", str, err)
+	}
+
+	// letters code 9 is D, not WRU
+	if _, ok := a.Receive([]byte{LS, 9}); ok {
+		t.Errorf("expect no response for D")
+	}
+}
+
+func TestMatchAnswerback(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		pattern    string
+		received   string
+		expect     bool
+		shouldFail bool
+	}{
+		{
+			caseName: "test matching answerback",
+			pattern:  `\d{5} BAUDOT D`,
+			received: "\r\n12345 BAUDOT D",
+			expect:   true,
+		},
+		{
+			caseName: "test mismatching answerback",
+			pattern:  `\d{5} BAUDOT D`,
+			received: "\r\n12345 BAUDOT DX",
+			expect:   false,
+		},
+		{
+			caseName:   "test invalid pattern",
+			pattern:    `(`,
+			received:   "12345",
+			shouldFail: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			ok, err := MatchAnswerback(tc.pattern, tc.received)
+			if (err != nil) != tc.shouldFail || ok != tc.expect {
+				t.Errorf("expect %v, got %v, %v", tc.expect, ok, err)
+			}
+		})
+	}
+}
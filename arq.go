@@ -0,0 +1,504 @@
+/*
+ * SITOR-A(also known as AMTOR mode A, or ARQ) is the point to point mode of CCIR 476.
+ * The information sending station(ISS) sends blocks of 3 signals, the information receiving station(IRS)
+ * answers each block with a control signal: alternating CS1/CS2 acknowledges a block, repeating the last one requests a repetition
+ * and CS3 requests a changeover. The ISS answers a mutilated control signal with an RQ block.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+type arqState byte
+
+const (
+	arqIdle arqState = iota
+	arqCalling
+	arqISS
+	arqIRS
+	// ISS has sent beta alpha beta and waits for confirmation
+	arqChangingOver
+	// IRS has confirmed changeover and becomes ISS on next cycle
+	arqPendingISS
+	// ISS has sent end of communication block
+	arqEnding
+)
+
+const (
+	blockLenARQ = 3
+	// number of unanswered cycles after which the ISS gives up the link
+	maxRetriesARQ = 32
+)
+
+var (
+	rqBlockARQ         = []byte{RQ_CCIR476, RQ_CCIR476, RQ_CCIR476}
+	idleBlockARQ       = []byte{BETA_CCIR476, BETA_CCIR476, BETA_CCIR476}
+	endBlockARQ        = []byte{ALPHA_CCIR476, ALPHA_CCIR476, ALPHA_CCIR476}
+	changeoverBlockARQ = []byte{BETA_CCIR476, ALPHA_CCIR476, BETA_CCIR476}
+)
+
+type arqStation struct {
+	address string
+	state   arqState
+
+	// ISS side
+	peer     string
+	outgoing []byte
+	block    []byte
+	lastCS   byte
+	rq       bool
+	ending   bool
+	retries  int
+	lost     bool
+	callStep int
+
+	// IRS side
+	cs          byte
+	reply       byte
+	callMatched bool
+	received    []byte
+	charset     Charset
+	lastChar    rune
+	changeover  bool
+}
+
+// NewARQStation creates a station answering to a 4 letters selective call address
+func NewARQStation(address string) *arqStation {
+	return &arqStation{
+		address: address,
+	}
+}
+
+// Call starts phasing with the station of the address, the calling station becomes ISS once the call is answered
+func (s *arqStation) Call(address string) error {
+	if len(address) != 4 {
+		return fmt.Errorf("Invalid Address: %s", address)
+	}
+	if _, err := callSignalsARQ(address); err != nil {
+		return err
+	}
+
+	s.peer = address
+	s.state = arqCalling
+	s.lastCS = 0
+	s.retries = 0
+	s.lost = false
+	s.callStep = 0
+
+	return nil
+}
+
+// Send queues a message, it's transmitted whenever the station is ISS
+func (s *arqStation) Send(msg string) error {
+	codes, err := encode(msg, false, versionITA2)
+	if err != nil {
+		return err
+	}
+
+	signals, err := ToCCIR476(codes)
+	if err != nil {
+		return err
+	}
+	s.outgoing = append(s.outgoing, signals...)
+
+	return nil
+}
+
+// Over queues the "+?" over signal, which asks the IRS to take the turn once the traffic before it is delivered
+func (s *arqStation) Over() error {
+	return s.Send("+?")
+}
+
+// RequestChangeover makes the station answer with CS3 next time it receives a block as IRS
+func (s *arqStation) RequestChangeover() {
+	s.changeover = true
+}
+
+// End sends the end of communication block once the queued traffic is delivered
+func (s *arqStation) End() {
+	s.ending = true
+}
+
+// Received returns the text received as IRS so far
+func (s *arqStation) Received() (string, error) {
+	return decode(s.received, false, versionITA2)
+}
+
+// Idle reports whether the station takes no part in a link
+func (s *arqStation) Idle() bool {
+	return s.state == arqIdle
+}
+
+// Lost reports whether the station gave up the link after too many unanswered cycles
+func (s *arqStation) Lost() bool {
+	return s.lost
+}
+
+func (s *arqStation) isSending() bool {
+	return s.state == arqCalling || s.state == arqISS || s.state == arqChangingOver || s.state == arqEnding
+}
+
+// transmitBlock returns the block the station sends as ISS for this cycle
+func (s *arqStation) transmitBlock() []byte {
+	if s.state == arqCalling {
+		signals, _ := callSignalsARQ(s.peer)
+		block := signals[s.callStep*blockLenARQ : (s.callStep+1)*blockLenARQ]
+		s.callStep ^= 1
+		return block
+	}
+
+	if s.rq {
+		return rqBlockARQ
+	}
+	if s.block == nil {
+		s.block = s.nextBlock()
+	}
+
+	return s.block
+}
+
+func (s *arqStation) nextBlock() []byte {
+	if len(s.outgoing) > 0 {
+		n := blockLenARQ
+		if len(s.outgoing) < n {
+			n = len(s.outgoing)
+		}
+		block := append([]byte{}, s.outgoing[:n]...)
+		s.outgoing = s.outgoing[n:]
+		for len(block) < blockLenARQ {
+			block = append(block, BETA_CCIR476)
+		}
+		return block
+	}
+
+	if s.ending {
+		s.state = arqEnding
+		return endBlockARQ
+	}
+
+	return idleBlockARQ
+}
+
+// receiveCS handles the control signal answering the last block as ISS
+func (s *arqStation) receiveCS(cs byte) {
+	switch s.state {
+	case arqCalling:
+		if cs == CS1_CCIR476 {
+			s.state = arqISS
+			s.lastCS = CS1_CCIR476
+			s.block = nil
+			s.retries = 0
+			return
+		}
+	case arqChangingOver:
+		s.rq = false
+		if cs == CS1_CCIR476 {
+			s.state = arqIRS
+			s.cs = CS1_CCIR476
+			s.reply = CS1_CCIR476
+			s.block = nil
+			s.resetReceiving()
+			return
+		}
+	case arqISS, arqEnding:
+		s.rq = false
+		if cs == CS3_CCIR476 && s.state == arqISS {
+			// CS3 acknowledges the block and requests changeover
+			s.state = arqChangingOver
+			s.block = changeoverBlockARQ
+			s.retries = 0
+			return
+		}
+
+		if (cs == CS1_CCIR476 || cs == CS2_CCIR476) && cs != s.lastCS {
+			s.lastCS = cs
+			s.block = nil
+			s.retries = 0
+			if s.state == arqEnding {
+				s.state = arqIdle
+				s.ending = false
+			}
+			return
+		}
+
+		if cs != s.lastCS {
+			// mutilated control signal, ask IRS to repeat it
+			s.rq = true
+		}
+	default:
+		return
+	}
+
+	s.retries++
+	if s.retries >= maxRetriesARQ {
+		s.state = arqIdle
+		s.lost = true
+		s.block = nil
+		s.rq = false
+	}
+}
+
+// receiveBlock handles a block as IRS(or as an idle station being called) and returns the control signal to answer with, 0 for no answer
+func (s *arqStation) receiveBlock(block []byte) byte {
+	switch s.state {
+	case arqIdle:
+		return s.receiveCall(block)
+	case arqPendingISS:
+		if isSameBlockARQ(block, changeoverBlockARQ) {
+			return CS1_CCIR476
+		}
+		return 0
+	case arqIRS:
+	default:
+		return 0
+	}
+
+	for _, signal := range block {
+		if !IsValidCCIR476(signal) {
+			// mutilated block, ask for repetition
+			return s.reply
+		}
+	}
+
+	switch {
+	case isSameBlockARQ(block, rqBlockARQ):
+		return s.reply
+	case isSameBlockARQ(block, changeoverBlockARQ):
+		s.state = arqPendingISS
+		s.changeover = false
+		return CS1_CCIR476
+	case isSameBlockARQ(block, endBlockARQ):
+		s.state = arqIdle
+		s.cs = s.toggleCS()
+		s.reply = s.cs
+		return s.reply
+	}
+
+	// a traffic block only carries traffic signals followed by beta padding, anything else is a mutilated block or a repeated call
+	var codes []byte
+	for i, signal := range block {
+		code, ok := ccir476ToITA2[signal]
+		if !ok {
+			if signal != BETA_CCIR476 || !isPaddingARQ(block[i:]) {
+				return s.reply
+			}
+			break
+		}
+		codes = append(codes, code)
+	}
+	for _, code := range codes {
+		s.received = append(s.received, code)
+		s.trackOver(code)
+	}
+
+	s.cs = s.toggleCS()
+	s.reply = s.cs
+	if s.changeover {
+		s.reply = CS3_CCIR476
+	}
+
+	return s.reply
+}
+
+func (s *arqStation) receiveCall(block []byte) byte {
+	signals, err := callSignalsARQ(s.address)
+	if err != nil {
+		return 0
+	}
+
+	if isSameBlockARQ(block, signals[:blockLenARQ]) {
+		s.callMatched = true
+		return 0
+	}
+	if s.callMatched && isSameBlockARQ(block, signals[blockLenARQ:]) {
+		s.callMatched = false
+		s.state = arqIRS
+		s.cs = CS1_CCIR476
+		s.reply = s.cs
+		s.resetReceiving()
+		return s.reply
+	}
+	s.callMatched = false
+
+	// the end of communication block is acknowledged again if the ISS didn't get the acknowledgement
+	if s.reply != 0 && (isSameBlockARQ(block, endBlockARQ) || isSameBlockARQ(block, rqBlockARQ)) {
+		return s.reply
+	}
+
+	return 0
+}
+
+func (s *arqStation) resetReceiving() {
+	s.charset = Letters
+	s.lastChar = 0
+}
+
+// trackOver follows the register of received codes to detect the "+?" over signal
+func (s *arqStation) trackOver(code byte) {
+	ch, shiftedCharset, err := decodeChar(code, s.charset, versionITA2)
+	if err != nil {
+		return
+	}
+	if shiftedCharset != s.charset {
+		s.charset = shiftedCharset
+		return
+	}
+	if ch == '?' && s.lastChar == '+' {
+		s.changeover = true
+	}
+	s.lastChar = ch
+}
+
+func (s *arqStation) toggleCS() byte {
+	if s.cs == CS1_CCIR476 {
+		return CS2_CCIR476
+	}
+
+	return CS1_CCIR476
+}
+
+// callSignalsARQ returns the 2 call blocks carrying a 4 letters address
+func callSignalsARQ(address string) ([]byte, error) {
+	codes := make([]byte, 0, len(address))
+	for _, char := range address {
+		code, charset, err := encodeChar(char, Letters, versionITA2)
+		if err != nil || charset != Letters {
+			return nil, fmt.Errorf("Invalid Address: %s", address)
+		}
+		codes = append(codes, code)
+	}
+
+	signals, err := ToCCIR476(codes)
+	if err != nil || len(signals) != 4 {
+		return nil, fmt.Errorf("Invalid Address: %s", address)
+	}
+
+	return []byte{RQ_CCIR476, signals[0], signals[1], signals[2], signals[3], RQ_CCIR476}, nil
+}
+
+func isPaddingARQ(signals []byte) bool {
+	for _, signal := range signals {
+		if signal != BETA_CCIR476 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isSameBlockARQ(a []byte, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+type lossyChannel struct {
+	rnd          *rand.Rand
+	bitErrorRate float64
+	lossRate     float64
+}
+
+// NewLossyChannel creates a seeded channel simulator flipping each bit with bitErrorRate probability and losing each signal with lossRate probability
+func NewLossyChannel(seed int64, bitErrorRate float64, lossRate float64) *lossyChannel {
+	return &lossyChannel{
+		rnd:          rand.New(rand.NewSource(seed)),
+		bitErrorRate: bitErrorRate,
+		lossRate:     lossRate,
+	}
+}
+
+// Transmit passes signals through the channel, lost signals are received as 0
+func (c *lossyChannel) Transmit(signals []byte) []byte {
+	out := make([]byte, len(signals))
+	for i, signal := range signals {
+		if c.rnd.Float64() < c.lossRate {
+			continue
+		}
+		for bit := 0; bit < 7; bit++ {
+			if c.rnd.Float64() < c.bitErrorRate {
+				signal ^= 1 << bit
+			}
+		}
+		out[i] = signal
+	}
+
+	return out
+}
+
+type arqLink struct {
+	a       *arqStation
+	b       *arqStation
+	channel *lossyChannel
+	cycles  int
+}
+
+// NewARQLink connects 2 stations through a channel, a nil channel is error free
+func NewARQLink(a *arqStation, b *arqStation, channel *lossyChannel) *arqLink {
+	if channel == nil {
+		channel = NewLossyChannel(0, 0, 0)
+	}
+
+	return &arqLink{
+		a:       a,
+		b:       b,
+		channel: channel,
+	}
+}
+
+// Step runs one ARQ cycle: a block from ISS to IRS and a control signal back, returns false if no station is sending
+func (l *arqLink) Step() bool {
+	iss, irs := l.a, l.b
+	if !iss.isSending() {
+		iss, irs = l.b, l.a
+	}
+	if !iss.isSending() {
+		if l.a.state == arqPendingISS {
+			iss, irs = l.a, l.b
+		} else if l.b.state == arqPendingISS {
+			iss, irs = l.b, l.a
+		} else {
+			return false
+		}
+		iss.state = arqISS
+		iss.lastCS = CS1_CCIR476
+		iss.block = nil
+		iss.retries = 0
+	}
+
+	l.cycles++
+	block := l.channel.Transmit(iss.transmitBlock())
+	cs := irs.receiveBlock(block)
+	received := l.channel.Transmit([]byte{cs})
+	iss.receiveCS(received[0])
+
+	return true
+}
+
+// Run steps the link until no station is sending or maxCycles is reached
+func (l *arqLink) Run(maxCycles int) error {
+	for i := 0; i < maxCycles; i++ {
+		if !l.Step() {
+			if l.a.lost || l.b.lost {
+				return fmt.Errorf("Link lost after %d cycles", l.cycles)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Link still active after %d cycles", maxCycles)
+}
+
+// Cycles returns the number of ARQ cycles run so far
+func (l *arqLink) Cycles() int {
+	return l.cycles
+}
@@ -0,0 +1,120 @@
+package baudot
+
+import (
+	"testing"
+)
+
+func TestARQLink(t *testing.T) {
+	tt := []struct {
+		caseName string
+		lossRate float64
+	}{
+		{
+			caseName: "test error free channel",
+		},
+		{
+			// lost signals are always detected, so every seed delivers the text
+			caseName: "test lossy channel",
+			lossRate: 0.1,
+		},
+		{
+			caseName: "test very lossy channel",
+			lossRate: 0.15,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			for seed := int64(1); seed <= 50; seed++ {
+				a, b := NewARQStation("AAAA"), NewARQStation("BBBB")
+				link := NewARQLink(a, b, NewLossyChannel(seed, 0, tc.lossRate))
+
+				if err := a.Call("BBBB"); err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				_ = a.Send("CQ DE AAAA 123")
+				a.End()
+
+				if err := link.Run(10000); err != nil {
+					t.Fatalf("seed %d: expect link to end, got %v", seed, err)
+				}
+
+				str, err := b.Received()
+				if err != nil || str != "CQ DE AAAA 123" {
+					t.Errorf("seed %d: expect 'CQ DE AAAA 123', got %v, %v", seed, str, err)
+				}
+				if !a.Idle() || !b.Idle() {
+					t.Errorf("seed %d: expect both stations idle", seed)
+				}
+			}
+		})
+	}
+}
+
+// TestARQUndetectedErrors documents the limit of the 4:3 check: a double bit error keeping the ratio is a valid code,
+// so on a noisy channel ARQ repeats most corrupted blocks but some corrupted text is still delivered
+func TestARQUndetectedErrors(t *testing.T) {
+	corrupted := 0
+	for seed := int64(1); seed <= 100; seed++ {
+		a, b := NewARQStation("AAAA"), NewARQStation("BBBB")
+		link := NewARQLink(a, b, NewLossyChannel(seed, 0.02, 0))
+
+		_ = a.Call("BBBB")
+		_ = a.Send("CQ DE AAAA 123")
+		a.End()
+
+		if err := link.Run(10000); err != nil {
+			t.Fatalf("seed %d: expect link to end, got %v", seed, err)
+		}
+		if str, _ := b.Received(); str != "CQ DE AAAA 123" {
+			corrupted++
+		}
+	}
+
+	if corrupted == 0 || corrupted > 20 {
+		t.Errorf("expect a few of 100 messages corrupted, got %d", corrupted)
+	}
+}
+
+func TestARQChangeover(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		a, b := NewARQStation("AAAA"), NewARQStation("BBBB")
+		link := NewARQLink(a, b, NewLossyChannel(seed, 0, 0.1))
+
+		_ = a.Call("BBBB")
+		_ = a.Send("QSL? ")
+		_ = a.Over()
+		_ = b.Send("QSL 5 ")
+		b.End()
+
+		if err := link.Run(10000); err != nil {
+			t.Fatalf("seed %d: expect link to end, got %v", seed, err)
+		}
+
+		if str, _ := b.Received(); str != "QSL? +?" {
+			t.Errorf("seed %d: expect B to receive 'QSL? +?', got %v", seed, str)
+		}
+		if str, _ := a.Received(); str != "QSL 5 " {
+			t.Errorf("seed %d: expect A to receive 'QSL 5 ', got %v", seed, str)
+		}
+		if !a.Idle() || !b.Idle() {
+			t.Errorf("seed %d: expect both stations idle", seed)
+		}
+	}
+}
+
+func TestARQCallUnanswered(t *testing.T) {
+	a, b := NewARQStation("AAAA"), NewARQStation("BBBB")
+	link := NewARQLink(a, b, nil)
+
+	if err := a.Call("CCCC"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := link.Run(1000); err == nil || !a.Lost() {
+		t.Errorf("expect link lost, got %v", err)
+	}
+
+	if err := a.Call("B1"); err == nil {
+		t.Errorf("expect an error for invalid address")
+	}
+}
@@ -1,6 +1,9 @@
 package baudot
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type Charset byte
 
@@ -9,7 +12,10 @@ type version byte
 const (
 	Letters Charset = 0
 	Figures Charset = 1
-	// Cyrillic Charset = 2
+	// the halves of the Japanese katakana register
+	Katakana         Charset = 2
+	KatakanaExtended Charset = 3
+	// Cyrillic Charset = 4
 )
 
 const (
@@ -22,12 +28,18 @@ const (
 	LS_ITA1 byte = 1
 	// ITA1 Shift to Figures
 	FS_ITA1 byte = 2
+	// Japanese Shift to Katakana, it switches halves within the katakana register
+	KS byte = 0
 )
 
 const (
-	versionITA1  version = 0
-	versionITA2  version = 1
-	versionUSTTY version = 2
+	versionITA1            version = 0
+	versionITA2            version = 1
+	versionUSTTY           version = 2
+	versionITA1Continental version = 3
+	versionMurray          version = 4
+	versionWeather         version = 5
+	versionKatakana        version = 6
 )
 
 type Codec interface {
@@ -35,27 +47,114 @@ type Codec interface {
 	Decode([]byte) (string, error)
 }
 
+type CodecErrorKind byte
+
+const (
+	// a character the variant doesn't have
+	InvalidChar CodecErrorKind = 1
+	// a code which is not a character or a shift code in the register
+	InvalidCode CodecErrorKind = 2
+	// a register the variant doesn't have
+	InvalidCharset CodecErrorKind = 3
+	// an ITA3 code without the 3 marks of a valid combination
+	MutilatedCode CodecErrorKind = 4
+)
+
+// CodecError is the error of a character or a code which can't be converted.
+// Position is the index of the rune in the message or of the code in the sequence, -1 when a single character or code is converted
+type CodecError struct {
+	Kind     CodecErrorKind
+	Char     rune
+	Code     byte
+	Charset  Charset
+	Position int
+}
+
+func (e *CodecError) Error() string {
+	switch e.Kind {
+	case InvalidChar:
+		return fmt.Sprintf("Invalid Char: %c", e.Char)
+	case InvalidCode:
+		return fmt.Sprintf("Invalid Code: %d", e.Code)
+	case MutilatedCode:
+		return fmt.Sprintf("Mutilated Code: %d", e.Code)
+	}
+
+	return fmt.Sprintf("Invalid Charset: %d", e.Charset)
+}
+
+// at sets the position of a codec error
+func at(err error, position int) error {
+	var e *CodecError
+	if errors.As(err, &e) {
+		e.Position = position
+	}
+
+	return err
+}
+
+// versioned is implemented by the codecs built on encode/decode, it lets stream components work on the underlying tables
+type versioned interface {
+	ver() version
+}
+
+// normalizing is implemented by the codecs which rewrite messages before encoding them
+type normalizing interface {
+	normalize(string) string
+}
+
+func normalize(codec Codec, msg string) string {
+	if n, ok := codec.(normalizing); ok {
+		return n.normalize(msg)
+	}
+
+	return msg
+}
+
 type ita1 struct {
-	ignErr bool
+	ignErr  bool
+	version version
 }
 
 type ita2 struct {
-	ignErr bool
+	ignErr  bool
+	version version
 }
 
 type ustty struct {
 	ignErr bool
 }
 
+type murray struct {
+	ignErr bool
+}
+
+type weather struct {
+	ignErr bool
+}
+
+type katakana struct {
+	ignErr bool
+}
+
 func NewITA1(ignoreError bool) *ita1 {
 	return &ita1{
-		ignErr: ignoreError,
+		ignErr:  ignoreError,
+		version: versionITA1,
+	}
+}
+
+func NewITA1Continental(ignoreError bool) *ita1 {
+	return &ita1{
+		ignErr:  ignoreError,
+		version: versionITA1Continental,
 	}
 }
 
 func NewITA2(ignoreError bool) *ita2 {
 	return &ita2{
-		ignErr: ignoreError,
+		ignErr:  ignoreError,
+		version: versionITA2,
 	}
 }
 
@@ -65,40 +164,52 @@ func NewUSTTY(ignoreError bool) *ustty {
 	}
 }
 
-// The sequence always starts with a null Control followed by a LS(Shift to Letters) Control
+func NewMurray(ignoreError bool) *murray {
+	return &murray{
+		ignErr: ignoreError,
+	}
+}
+
+func NewWeather(ignoreError bool) *weather {
+	return &weather{
+		ignErr: ignoreError,
+	}
+}
+
+func NewKatakana(ignoreError bool) *katakana {
+	return &katakana{
+		ignErr: ignoreError,
+	}
+}
+
+// The sequence always starts with a null Control followed by a LS(Shift to Letters) Control,
+// ITA1 has no null Control so it starts with LS_ITA1 only, neither does the Japanese code which uses the null code as KS
 func encode(msg string, ignoreError bool, ver version) ([]byte, error) {
 	var (
-		shifters       [2]byte
-		shiftersITA2   = [2]byte{LS, FS}
-		shiftersITA1   = [2]byte{LS_ITA1, FS_ITA1}
 		currentCharset = Letters
-		codes          = []byte{NULL, LS}
+		codes          = startCodes(ver, Letters)
 	)
 
+	position := -1
 	for _, char := range msg {
-		if ver == versionITA1 {
-			shifters = shiftersITA1
-		} else if ver == versionITA2 || ver == versionUSTTY {
-			shifters = shiftersITA2
-		} else if ignoreError {
-			break
-		} else {
-			return nil, fmt.Errorf("")
-		}
-
+		position++
 		code, shiftedCharset, err := encodeChar(char, currentCharset, ver)
 
 		if err != nil {
 			if ignoreError {
 				continue
 			} else {
-				return nil, err
+				return nil, at(err, position)
 			}
 		}
 
 		if currentCharset != shiftedCharset {
+			shifters, err := shiftPath(ver, currentCharset, shiftedCharset)
+			if err != nil {
+				return nil, err
+			}
 			currentCharset = shiftedCharset
-			codes = append(codes, shifters[currentCharset])
+			codes = append(codes, shifters...)
 		}
 
 		codes = append(codes, code)
@@ -108,17 +219,22 @@ func encode(msg string, ignoreError bool, ver version) ([]byte, error) {
 }
 
 func decode(codes []byte, ignoreError bool, ver version) (string, error) {
+	return decodeUSOS(codes, ignoreError, ver, false)
+}
+
+// decodeUSOS decodes like decode, with unshiftOnSpace the register returns to Letters after every space(USOS)
+func decodeUSOS(codes []byte, ignoreError bool, ver version, unshiftOnSpace bool) (string, error) {
 	var str []rune
 	currentCharset := Letters
 
-	for _, eachCode := range codes {
+	for i, eachCode := range codes {
 		ch, shiftedCharset, err := decodeChar(eachCode, currentCharset, ver)
 
 		if err != nil {
 			if ignoreError {
 				continue
 			} else {
-				return "", err
+				return "", at(err, i)
 			}
 		}
 
@@ -131,6 +247,10 @@ func decode(codes []byte, ignoreError bool, ver version) (string, error) {
 			continue
 		}
 
+		if unshiftOnSpace && ch == ' ' {
+			currentCharset = Letters
+		}
+
 		str = append(str, ch)
 	}
 
@@ -138,73 +258,59 @@ func decode(codes []byte, ignoreError bool, ver version) (string, error) {
 }
 
 func encodeChar(char rune, currentCharset Charset, ver version) (byte, Charset, error) {
-	var (
-		shiftedCharset = currentCharset
-		charValues     [2]int8
-		ok             bool
-	)
-
-	if ver == versionITA1 {
-		charValues, ok = charmapITA1[char]
-	} else if ver == versionITA2 {
-		charValues, ok = charmapITA2[char]
-	} else if ver == versionUSTTY {
-		charValues, ok = charmapUSTTY[char]
-	} else {
-		return '\u0000', currentCharset, fmt.Errorf("Unsupported version: %d", ver)
+	t, err := lookupTables(ver)
+	if err != nil {
+		return '\u0000', currentCharset, err
+	}
+	if int(currentCharset) >= len(t.registers) {
+		return '\u0000', currentCharset, &CodecError{Kind: InvalidCharset, Charset: currentCharset, Position: -1}
 	}
 
+	charValues, ok := t.charmap[char]
 	if !ok {
 		// always return error, not affect by ignErr field
-		return 0, currentCharset, fmt.Errorf("Invalid Char: %c", char)
+		return 0, currentCharset, &CodecError{Kind: InvalidChar, Char: char, Position: -1}
 	}
 
-	code := charValues[currentCharset]
-	if code == -1 {
-		shiftedCharset = Charset(currentCharset ^ 1)
-		code = charValues[shiftedCharset]
+	if charValues[currentCharset] != -1 {
+		return byte(charValues[currentCharset]), currentCharset, nil
 	}
 
-	return byte(code), shiftedCharset, nil
+	// shift to the nearest register having the character
+	shiftedCharset, shifts := currentCharset, -1
+	for charset, code := range charValues {
+		if code == -1 {
+			continue
+		}
+		path, err := t.shiftPath(currentCharset, Charset(charset))
+		if err == nil && (shifts == -1 || len(path) < shifts) {
+			shiftedCharset, shifts = Charset(charset), len(path)
+		}
+	}
+	if shifts == -1 {
+		return 0, currentCharset, &CodecError{Kind: InvalidChar, Char: char, Position: -1}
+	}
+
+	return byte(charValues[shiftedCharset]), shiftedCharset, nil
 }
 
 func decodeChar(code byte, currentCharset Charset, ver version) (rune, Charset, error) {
-	var charset map[byte]rune
-
-	if ver == versionITA1 {
-		if code == LS_ITA1 {
-			return '\u0000', Letters, nil
-		} else if code == FS_ITA1 {
-			return '\u0000', Figures, nil
-		}
-
-		if currentCharset == Letters {
-			charset = lettersITA1
-		} else {
-			charset = figuresITA1
-		}
-	} else if ver == versionITA2 || ver == versionUSTTY {
-		if code == LS {
-			return '\u0000', Letters, nil
-		} else if code == FS {
-			return '\u0000', Figures, nil
-		}
+	t, err := lookupTables(ver)
+	if err != nil {
+		return '\u0000', currentCharset, err
+	}
+	if int(currentCharset) >= len(t.registers) {
+		return '\u0000', currentCharset, &CodecError{Kind: InvalidCharset, Charset: currentCharset, Position: -1}
+	}
 
-		if currentCharset == Letters {
-			charset = lettersITA2
-		} else if ver == versionITA2 {
-			charset = figuresITA2
-		} else {
-			charset = figuresUSTTY
-		}
-	} else {
-		return '\u0000', currentCharset, fmt.Errorf("Unsupported version: %d", ver)
+	if shiftedCharset, ok := t.shifts[currentCharset][code]; ok {
+		return '\u0000', shiftedCharset, nil
 	}
 
-	char, ok := charset[code]
+	char, ok := t.registers[currentCharset][code]
 	if !ok {
 		// always return error, not affect by ignErr field
-		return '\u0000', currentCharset, fmt.Errorf("Invalid Code: %d", code)
+		return '\u0000', currentCharset, &CodecError{Kind: InvalidCode, Code: code, Position: -1}
 	}
 
 	return char, currentCharset, nil
@@ -0,0 +1,270 @@
+/*
+ * Package baudottest checks a codec behaves like a Baudot variant, so built-in and custom variants are validated the same way.
+ * It only uses Encode and Decode: the registers are found by decoding, a code decoding to nothing which changes how the following codes decode
+ * is a shift code, and every register reached through shift codes is checked.
+ */
+
+package baudottest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hsldymq/baudot"
+)
+
+// NewCodec creates the codec under test with the error policy
+type NewCodec func(ignoreError bool) baudot.Codec
+
+type Options struct {
+	// Normalize is applied to the expected and the decoded text before comparing them, e.g. for codecs composing characters
+	Normalize func(string) string
+}
+
+func (o Options) normalize(s string) string {
+	if o.Normalize == nil {
+		return s
+	}
+
+	return o.Normalize(s)
+}
+
+// Register is a register found by decoding
+type Register struct {
+	// Shift is the shortest sequence of shift codes taking the receiver from its start register to this one
+	Shift []byte
+	// Chars are the characters of the register by code
+	Chars map[byte]rune
+}
+
+// an invalid code in every 5 bit variant
+const invalidCode byte = 0xFF
+
+// a character in no variant
+const invalidChar rune = '\uE000'
+
+// Registers finds the registers of a codec, the start register comes first
+func Registers(newCodec NewCodec) ([]Register, error) {
+	codec := newCodec(true)
+
+	signature := func(prefix []byte) ([32]string, error) {
+		var s [32]string
+		for code := range s {
+			str, err := codec.Decode(append(append([]byte{}, prefix...), byte(code)))
+			if err != nil {
+				return s, err
+			}
+			s[code] = str
+		}
+		return s, nil
+	}
+
+	start, err := signature(nil)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[[32]string]bool{start: true}
+	registers := []Register{{Shift: []byte{}, Chars: chars(start)}}
+	for i := 0; i < len(registers); i++ {
+		for code := 0; code < 32; code++ {
+			shift := append(append([]byte{}, registers[i].Shift...), byte(code))
+			if str, err := codec.Decode(shift); err != nil || str != "" {
+				continue
+			}
+			s, err := signature(shift)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[s] {
+				seen[s] = true
+				registers = append(registers, Register{Shift: shift, Chars: chars(s)})
+			}
+		}
+	}
+
+	return registers, nil
+}
+
+// chars returns the codes decoding to a single character
+func chars(signature [32]string) map[byte]rune {
+	chars := map[byte]rune{}
+	for code, str := range signature {
+		if runes := []rune(str); len(runes) == 1 {
+			chars[byte(code)] = runes[0]
+		}
+	}
+
+	return chars
+}
+
+// Run runs the conformance checks on a codec as subtests of t
+func Run(t *testing.T, newCodec NewCodec, options Options) {
+	registers, err := Registers(newCodec)
+	if err != nil {
+		t.Fatalf("expect registers, got %v", err)
+	}
+
+	t.Run("registers", func(t *testing.T) {
+		for i, register := range registers {
+			if len(register.Chars) == 0 {
+				t.Errorf("register %d(shift %v) has no character", i, register.Shift)
+			}
+		}
+	})
+
+	t.Run("every code", func(t *testing.T) {
+		codec := newCodec(false)
+		for _, register := range registers {
+			for code, char := range register.Chars {
+				str, err := codec.Decode(append(append([]byte{}, register.Shift...), code))
+				if err != nil || str != string(char) {
+					t.Errorf("code %d after %v: expect %q, got %q, %v", code, register.Shift, char, str, err)
+				}
+				checkRoundTrip(t, codec, string(char), options)
+			}
+		}
+	})
+
+	t.Run("shift transitions", func(t *testing.T) {
+		codec := newCodec(false)
+		for _, from := range registers {
+			for _, to := range registers {
+				for _, a := range from.Chars {
+					for _, b := range to.Chars {
+						checkRoundTrip(t, codec, string([]rune{a, b, a}), options)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var valid rune
+		for _, char := range registers[0].Chars {
+			if valid == 0 || char < valid {
+				valid = char
+			}
+		}
+		msg := string([]rune{valid, invalidChar, valid})
+
+		if codes, err := newCodec(false).Encode(msg); err == nil {
+			t.Errorf("expect error encoding %q, got %v", msg, codes)
+		}
+		if _, err := newCodec(false).Decode([]byte{invalidCode}); err == nil {
+			t.Errorf("expect error decoding %v", invalidCode)
+		}
+
+		lenient := newCodec(true)
+		codes, err := lenient.Encode(msg)
+		if err != nil {
+			t.Errorf("expect %q encoded ignoring errors, got %v", msg, err)
+		}
+		str, err := lenient.Decode(append(codes, invalidCode))
+		want := options.normalize(string([]rune{valid, valid}))
+		if err != nil || options.normalize(str) != want {
+			t.Errorf("expect %q ignoring errors, got %q, %v", want, str, err)
+		}
+	})
+}
+
+func checkRoundTrip(t *testing.T, codec baudot.Codec, msg string, options Options) {
+	t.Helper()
+
+	codes, err := codec.Encode(msg)
+	if err != nil {
+		t.Errorf("expect %q encoded, got %v", msg, err)
+		return
+	}
+	str, err := codec.Decode(codes)
+	if err != nil || options.normalize(str) != options.normalize(msg) {
+		t.Errorf("expect %q from %v, got %q, %v", msg, codes, str, err)
+	}
+}
+
+// Seeds returns strings of the characters of each register, a seed corpus for fuzzing
+func Seeds(newCodec NewCodec) []string {
+	registers, err := Registers(newCodec)
+	if err != nil {
+		return nil
+	}
+
+	var seeds []string
+	for _, register := range registers {
+		var runes []rune
+		for code := 0; code < 32; code++ {
+			if char, ok := register.Chars[byte(code)]; ok {
+				runes = append(runes, char)
+			}
+		}
+		seeds = append(seeds, string(runes))
+	}
+
+	return seeds
+}
+
+// FuzzEncode fuzzes Encode: the text decoded from the codes must encode to codes decoding to the same text, and ignoring errors Encode never fails
+func FuzzEncode(f *testing.F, newCodec NewCodec) {
+	for _, seed := range Seeds(newCodec) {
+		f.Add(seed)
+	}
+	f.Add(string(invalidChar))
+
+	f.Fuzz(func(t *testing.T, msg string) {
+		codec := newCodec(false)
+		if codes, err := codec.Encode(msg); err == nil {
+			if err := checkReencode(codec, codes); err != nil {
+				t.Errorf("%q: %v", msg, err)
+			}
+		}
+
+		lenient := newCodec(true)
+		codes, err := lenient.Encode(msg)
+		if err != nil {
+			t.Fatalf("expect %q encoded ignoring errors, got %v", msg, err)
+		}
+		if _, err := lenient.Decode(codes); err != nil {
+			t.Errorf("expect %v decoded, got %v", codes, err)
+		}
+	})
+}
+
+// FuzzDecode fuzzes Decode: decoded text must encode to codes decoding to the same text, and ignoring errors Decode never fails
+func FuzzDecode(f *testing.F, newCodec NewCodec) {
+	for _, seed := range Seeds(newCodec) {
+		codes, err := newCodec(true).Encode(seed)
+		if err == nil {
+			f.Add(codes)
+		}
+	}
+	f.Add([]byte{invalidCode})
+
+	f.Fuzz(func(t *testing.T, codes []byte) {
+		codec := newCodec(false)
+		if _, err := codec.Decode(codes); err == nil {
+			if err := checkReencode(codec, codes); err != nil {
+				t.Error(err)
+			}
+		}
+
+		if _, err := newCodec(true).Decode(codes); err != nil {
+			t.Errorf("expect %v decoded ignoring errors, got %v", codes, err)
+		}
+	})
+}
+
+// checkReencode checks the text decoded from codes encodes to codes decoding to the same text
+func checkReencode(codec baudot.Codec, codes []byte) error {
+	str, err := codec.Decode(codes)
+	if err != nil {
+		return fmt.Errorf("expect %v decoded, got %v", codes, err)
+	}
+	reencoded, err := codec.Encode(str)
+	if err != nil {
+		return fmt.Errorf("expect %q encoded, got %v", str, err)
+	}
+	if again, err := codec.Decode(reencoded); err != nil || again != str {
+		return fmt.Errorf("expect %q from %v, got %q, %v", str, reencoded, again, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+ * CCIR 476(ITU-R M.476) is the 7-unit error detecting code used by SITOR, AMTOR and NAVTEX.
+ * Each ITA2 code maps to a 7-unit combination with exactly 4 marks and 3 spaces(the 4:3 ratio),
+ * so any single bit error turns a valid signal into an invalid one.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+const (
+	// CCIR 476 phasing signal 1, also used as idle signal
+	ALPHA_CCIR476 byte = 0x0F
+	// CCIR 476 idle signal beta
+	BETA_CCIR476 byte = 0x33
+	// CCIR 476 phasing signal 2, also used as request for repetition(RQ)
+	RQ_CCIR476 byte = 0x66
+)
+
+// Control signals sent by the information receiving station in ARQ mode,
+// they travel in the opposite direction of the traffic so they share combinations with traffic signals
+const (
+	CS1_CCIR476 byte = 0x2B
+	CS2_CCIR476 byte = 0x35
+	CS3_CCIR476 byte = 0x59
+)
+
+// ita2ToCCIR476 is indexed by ITA2 code
+var ita2ToCCIR476 = [32]byte{
+	0x6A, // blank
+	0x56, // E 3
+	0x6C, // LF
+	0x47, // A -
+	0x5C, // space
+	0x4B, // S '
+	0x4D, // I 8
+	0x4E, // U 7
+	0x78, // CR
+	0x53, // D WRU
+	0x55, // R 4
+	0x17, // J bell
+	0x59, // N ,
+	0x1B, // F !
+	0x1D, // C :
+	0x1E, // K (
+	0x74, // T 5
+	0x63, // Z +
+	0x65, // L )
+	0x27, // W 2
+	0x69, // H £
+	0x2B, // Y 6
+	0x2D, // P 0
+	0x2E, // Q 1
+	0x71, // O 9
+	0x72, // B ?
+	0x35, // G &
+	0x36, // FS
+	0x39, // M .
+	0x3A, // X /
+	0x3C, // V =
+	0x5A, // LS
+}
+
+var ccir476ToITA2 = func() map[byte]byte {
+	m := make(map[byte]byte, len(ita2ToCCIR476))
+	for code, signal := range ita2ToCCIR476 {
+		m[signal] = byte(code)
+	}
+
+	return m
+}()
+
+// IsValidCCIR476 reports whether the signal has the 4:3 mark/space ratio of a CCIR 476 combination
+func IsValidCCIR476(signal byte) bool {
+	return signal < 0x80 && bits.OnesCount8(signal) == 4
+}
+
+// ToCCIR476 converts a sequence of ITA2 codes into CCIR 476 signals
+func ToCCIR476(codes []byte) ([]byte, error) {
+	signals := make([]byte, 0, len(codes))
+	for _, code := range codes {
+		if int(code) >= len(ita2ToCCIR476) {
+			return nil, fmt.Errorf("Invalid Code: %d", code)
+		}
+		signals = append(signals, ita2ToCCIR476[code])
+	}
+
+	return signals, nil
+}
+
+// FromCCIR476 converts a sequence of CCIR 476 signals back into ITA2 codes,
+// service signals(alpha, beta, RQ) and signals failing the 4:3 ratio check are reported as errors
+func FromCCIR476(signals []byte) ([]byte, error) {
+	codes := make([]byte, 0, len(signals))
+	for _, signal := range signals {
+		code, ok := ccir476ToITA2[signal]
+		if !ok {
+			return nil, fmt.Errorf("Invalid Signal: %#x", signal)
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
@@ -66,6 +66,131 @@ var figuresITA1 = map[byte]rune{
 	31: '+',
 }
 
+// continental version of ITA1, the figures positions holding '\u0000' are fraction signs without a Unicode counterpart
+var lettersITA1Continental = map[byte]rune{
+	0:  ' ',
+	3:  '*',
+	4:  'A',
+	5:  '-',
+	6:  'J',
+	7:  'K',
+	8:  'E',
+	9:  'X',
+	10: 'G',
+	11: 'M',
+	12: 'É',
+	13: 'Z',
+	14: 'H',
+	15: 'L',
+	16: 'Y',
+	17: 'S',
+	18: 'B',
+	19: 'R',
+	20: 'U',
+	21: 'T',
+	22: 'C',
+	23: 'Q',
+	24: 'I',
+	25: 'W',
+	26: 'F',
+	27: 'N',
+	28: 'O',
+	29: 'V',
+	30: 'D',
+	31: 'P',
+}
+
+var figuresITA1Continental = map[byte]rune{
+	0:  ' ',
+	3:  '*',
+	4:  '1',
+	5:  '.',
+	6:  '6',
+	7:  '(',
+	8:  '2',
+	9:  ',',
+	10: '7',
+	11: ')',
+	12: '&',
+	13: ':',
+	14: '\u0000',
+	15: '=',
+	16: '3',
+	17: ';',
+	18: '8',
+	19: '-',
+	20: '4',
+	21: '!',
+	22: '9',
+	23: '/',
+	24: 'º',
+	25: '?',
+	26: '\u0000',
+	27: '№',
+	28: '5',
+	29: '\'',
+	30: '0',
+	31: '%',
+}
+
+var charmapITA1Continental = map[rune][2]int8{
+	' ':  {0, 0},
+	'*':  {3, 3},
+	'A':  {4, -1},
+	'-':  {5, 19},
+	'J':  {6, -1},
+	'K':  {7, -1},
+	'E':  {8, -1},
+	'X':  {9, -1},
+	'G':  {10, -1},
+	'M':  {11, -1},
+	'É':  {12, -1},
+	'Z':  {13, -1},
+	'H':  {14, -1},
+	'L':  {15, -1},
+	'Y':  {16, -1},
+	'S':  {17, -1},
+	'B':  {18, -1},
+	'R':  {19, -1},
+	'U':  {20, -1},
+	'T':  {21, -1},
+	'C':  {22, -1},
+	'Q':  {23, -1},
+	'I':  {24, -1},
+	'W':  {25, -1},
+	'F':  {26, -1},
+	'N':  {27, -1},
+	'O':  {28, -1},
+	'V':  {29, -1},
+	'D':  {30, -1},
+	'P':  {31, -1},
+	'1':  {-1, 4},
+	'.':  {-1, 5},
+	'6':  {-1, 6},
+	'(':  {-1, 7},
+	'2':  {-1, 8},
+	',':  {-1, 9},
+	'7':  {-1, 10},
+	')':  {-1, 11},
+	'&':  {-1, 12},
+	':':  {-1, 13},
+	'=':  {-1, 15},
+	'3':  {-1, 16},
+	';':  {-1, 17},
+	'8':  {-1, 18},
+	'4':  {-1, 20},
+	'!':  {-1, 21},
+	'9':  {-1, 22},
+	'/':  {-1, 23},
+	'º':  {-1, 24},
+	'?':  {-1, 25},
+	'№':  {-1, 27},
+	'5':  {-1, 28},
+	'\'': {-1, 29},
+	'0':  {-1, 30},
+	'%':  {-1, 31},
+}
+
 var lettersITA2 = map[byte]rune{
 	0:  '\u0000',
 	1:  'E',
@@ -197,25 +322,25 @@ var charmapITA1 = map[rune][2]int8{
 	'V':      {29, -1},
 	'D':      {30, -1},
 	'P':      {31, -1},
-	'1':      {4, -1},
-	'.':      {5, -1},
-	'6':      {6, -1},
-	'(':      {7, -1},
-	'2':      {8, -1},
-	'7':      {10, -1},
-	')':      {11, -1},
-	':':      {13, -1},
-	'=':      {15, -1},
-	'3':      {16, -1},
-	'8':      {18, -1},
-	'4':      {20, -1},
-	'9':      {22, -1},
-	'?':      {25, -1},
-	'£':      {27, -1},
-	'5':      {28, -1},
-	'\'':     {29, -1},
-	'0':      {30, -1},
-	'+':      {31, -1},
+	'1':      {-1, 4},
+	'.':      {-1, 5},
+	'6':      {-1, 6},
+	'(':      {-1, 7},
+	'2':      {-1, 8},
+	'7':      {-1, 10},
+	')':      {-1, 11},
+	':':      {-1, 13},
+	'=':      {-1, 15},
+	'3':      {-1, 16},
+	'8':      {-1, 18},
+	'4':      {-1, 20},
+	'9':      {-1, 22},
+	'?':      {-1, 25},
+	'£':      {-1, 27},
+	'5':      {-1, 28},
+	'\'':     {-1, 29},
+	'0':      {-1, 30},
+	'+':      {-1, 31},
 }
 
 var charmapITA2 = map[rune][2]int8{
@@ -335,3 +460,173 @@ var charmapUSTTY = map[rune][2]int8{
 	'/':      {-1, 29},
 	';':      {-1, 30},
 }
+
+// figures of US weather networks, wind directions and sky cover symbols replace most punctuation of figuresUSTTY
+var figuresWeather = map[byte]rune{
+	0:  '\u0000',
+	1:  '3',
+	2:  '\n',
+	3:  '↑',
+	4:  ' ',
+	5:  '\u0007',
+	6:  '8',
+	7:  '7',
+	8:  '\r',
+	9:  '↗',
+	10: '4',
+	11: '↙',
+	12: '◑',
+	13: '→',
+	14: '○',
+	15: '←',
+	16: '5',
+	17: '+',
+	18: '↖',
+	19: '2',
+	20: '↓',
+	21: '6',
+	22: '0',
+	23: '1',
+	24: '9',
+	25: '⊕',
+	26: '↘',
+	28: '.',
+	29: '/',
+	30: '⦶',
+}
+
+var charmapWeather = map[rune][2]int8{
+	'\u0000': {0, 0},
+	'E':      {1, -1},
+	'\n':     {2, 2},
+	'A':      {3, -1},
+	' ':      {4, 4},
+	'S':      {5, -1},
+	'I':      {6, -1},
+	'U':      {7, -1},
+	'\r':     {8, 8},
+	'D':      {9, -1},
+	'R':      {10, -1},
+	'J':      {11, -1},
+	'N':      {12, -1},
+	'F':      {13, -1},
+	'C':      {14, -1},
+	'K':      {15, -1},
+	'T':      {16, -1},
+	'Z':      {17, -1},
+	'L':      {18, -1},
+	'W':      {19, -1},
+	'H':      {20, -1},
+	'Y':      {21, -1},
+	'P':      {22, -1},
+	'Q':      {23, -1},
+	'O':      {24, -1},
+	'B':      {25, -1},
+	'G':      {26, -1},
+	'M':      {28, -1},
+	'X':      {29, -1},
+	'V':      {30, -1},
+	'3':      {-1, 1},
+	'↑':      {-1, 3},
+	'\u0007': {-1, 5},
+	'8':      {-1, 6},
+	'7':      {-1, 7},
+	'↗':      {-1, 9},
+	'4':      {-1, 10},
+	'↙':      {-1, 11},
+	'◑':      {-1, 12},
+	'→':      {-1, 13},
+	'○':      {-1, 14},
+	'←':      {-1, 15},
+	'5':      {-1, 16},
+	'+':      {-1, 17},
+	'↖':      {-1, 18},
+	'2':      {-1, 19},
+	'↓':      {-1, 20},
+	'6':      {-1, 21},
+	'0':      {-1, 22},
+	'1':      {-1, 23},
+	'9':      {-1, 24},
+	'⊕':      {-1, 25},
+	'↘':      {-1, 26},
+	'.':      {-1, 28},
+	'/':      {-1, 29},
+	'⦶':      {-1, 30},
+	// glyphs close to the symbols above
+	'⬆': {-1, 3},
+	'⬈': {-1, 9},
+	'➡': {-1, 13},
+	'⬊': {-1, 26},
+	'⬇': {-1, 20},
+	'⬋': {-1, 11},
+	'⬅': {-1, 15},
+	'⬉': {-1, 18},
+	'◯': {-1, 14},
+	'⨁': {-1, 25},
+	'◐': {-1, 12},
+	'⊘': {-1, 30},
+}
+
+var kanaKatakana = map[byte]rune{
+	1:  'ア',
+	2:  '\n',
+	3:  'イ',
+	4:  ' ',
+	5:  'ウ',
+	6:  'エ',
+	7:  'オ',
+	8:  '\r',
+	9:  'カ',
+	10: 'キ',
+	11: 'ク',
+	12: 'ケ',
+	13: 'コ',
+	14: 'サ',
+	15: 'シ',
+	16: 'ス',
+	17: 'セ',
+	18: 'ソ',
+	19: 'タ',
+	20: 'チ',
+	21: 'ツ',
+	22: 'テ',
+	23: 'ト',
+	24: 'ナ',
+	25: 'ニ',
+	26: 'ヌ',
+	28: 'ネ',
+	29: 'ノ',
+	30: 'ハ',
+}
+
+var kanaKatakanaExtended = map[byte]rune{
+	1:  'ヒ',
+	2:  '\n',
+	3:  'フ',
+	4:  ' ',
+	5:  'ヘ',
+	6:  'ホ',
+	7:  'マ',
+	8:  '\r',
+	9:  'ミ',
+	10: 'ム',
+	11: 'メ',
+	12: 'モ',
+	13: 'ヤ',
+	14: 'ユ',
+	15: 'ヨ',
+	16: 'ラ',
+	17: 'リ',
+	18: 'ル',
+	19: 'レ',
+	20: 'ロ',
+	21: 'ワ',
+	22: 'ヲ',
+	23: 'ン',
+	24: '゛',
+	25: '゜',
+	26: 'ー',
+	28: '、',
+	29: '。',
+	30: '・',
+}
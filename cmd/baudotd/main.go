@@ -0,0 +1,73 @@
+/*
+ * baudotd serves the codecs over HTTP(see baudot.NewHandler), e.g.
+ *
+ *	baudotd -addr :8080 -variants ./variants
+ *	curl -d '{"variant": "ITA2", "text": "HELLO"}' localhost:8080/encode
+ *
+ * Every JSON variant definition in the -variants directory is loaded at startup, a definition using the name of another variant
+ * with different tables stops the server.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hsldymq/baudot"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	variants := flag.String("variants", "", "directory of variant definitions to load")
+	maxBody := flag.Int64("max-body", 0, "largest request body in bytes, 64KiB by default")
+	maxRender := flag.Int("max-render", 0, "longest tape rendered in codes, 4096 by default")
+	maxPixels := flag.Int("max-pixels", 0, "largest tape image rendered in pixels, 4M by default")
+	flag.Parse()
+
+	if *variants != "" {
+		if err := loadVariants(*variants); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	handler, err := baudot.NewHandler(baudot.HandlerOptions{MaxBodySize: *maxBody, MaxRenderCodes: *maxRender, MaxRenderPixels: *maxPixels})
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+	log.Printf("listening on %s", *addr)
+	log.Fatal(server.ListenAndServe())
+}
+
+func loadVariants(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		codec, err := baudot.LoadVariant(f, false)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		log.Printf("loaded %s from %s", codec.Name(), path)
+	}
+
+	return nil
+}
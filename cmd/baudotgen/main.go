@@ -0,0 +1,235 @@
+/*
+ * baudotgen generates Go tables from a variant definition(see baudot.VariantDefinition), e.g.
+ *
+ *	//go:generate go run github.com/hsldymq/baudot/cmd/baudotgen -package telex -o variant.go variant.json
+ *
+ * The tables are built and verified by the library(see baudot.VariantDefinition.Tables), like the runtime loader does, before anything is written.
+ * In package baudot it writes the decoding and encoding tables of a built-in variant and the function returning its tables,
+ * in any other package the definition and a constructor of its codec.
+ */
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hsldymq/baudot"
+)
+
+func main() {
+	pkg := flag.String("package", "baudot", "package of the generated file")
+	name := flag.String("name", "", "Go name of the variant, derived from the name of the definition by default")
+	output := flag.String("o", "", "generated file, standard output by default")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: baudotgen [flags] definition.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := generate(flag.Arg(0), *pkg, *name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "baudotgen:", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "baudotgen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the formatted source generated from the definition file
+func generate(path string, pkg string, name string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	def, err := baudot.ParseVariant(data)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := def.Tables()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = identifier(def.Name, true)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by baudotgen from %s. DO NOT EDIT.\n\npackage %s\n\n", filepath.ToSlash(path), pkg)
+	if pkg == "baudot" {
+		writeTables(&buf, tables, name)
+	} else {
+		writeDefinition(&buf, def, name)
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// writeTables writes the tables of a built-in variant
+func writeTables(buf *bytes.Buffer, tables *baudot.VariantTables, name string) {
+	var registerVars []string
+	for i, register := range tables.Decoding {
+		v := identifier(tables.Registers[i], false) + name
+		registerVars = append(registerVars, v)
+		fmt.Fprintf(buf, "var %s = map[byte]rune{\n", v)
+		for _, code := range sortedCodes(register) {
+			fmt.Fprintf(buf, "%d: %s,\n", code, quote(register[code]))
+		}
+		buf.WriteString("}\n\n")
+	}
+
+	charmap := tables.Encoding
+	chars := make([]rune, 0, len(charmap))
+	for char := range charmap {
+		chars = append(chars, char)
+	}
+	// characters by register then code, as the hand written tables are
+	sort.Slice(chars, func(i, j int) bool {
+		a, b := firstCode(charmap[chars[i]]), firstCode(charmap[chars[j]])
+		if a != b {
+			return a < b
+		}
+		return chars[i] < chars[j]
+	})
+	fmt.Fprintf(buf, "var charmap%s = map[rune][]int8{\n", name)
+	for _, char := range chars {
+		var values []string
+		for _, code := range charmap[char] {
+			values = append(values, fmt.Sprint(code))
+		}
+		fmt.Fprintf(buf, "%s: {%s},\n", quote(char), strings.Join(values, ", "))
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "// %sTables returns the tables of %s\n", identifier(name, false), tables.Name)
+	fmt.Fprintf(buf, "func %sTables() *tables {\nreturn &tables{\n", identifier(name, false))
+	fmt.Fprintf(buf, "name: %q,\n", tables.Name)
+	fmt.Fprintf(buf, "names: %#v,\n", tables.Registers)
+	fmt.Fprintf(buf, "registers: []map[byte]rune{%s},\n", strings.Join(registerVars, ", "))
+	fmt.Fprintf(buf, "charmap: charmap%s,\n", name)
+	buf.WriteString("shifts: []map[byte]Charset{\n")
+	for _, shift := range tables.Shifts {
+		var entries []string
+		for _, code := range sortedCodes(shift) {
+			entries = append(entries, fmt.Sprintf("%d: %d", code, shift[code]))
+		}
+		fmt.Fprintf(buf, "{%s},\n", strings.Join(entries, ", "))
+	}
+	buf.WriteString("},\n")
+	if len(tables.Start) > 0 {
+		fmt.Fprintf(buf, "start: []byte{%s},\n", strings.Trim(fmt.Sprint(tables.Start), "[]"))
+	}
+	buf.WriteString("}\n}\n")
+}
+
+// writeDefinition writes the definition and the constructor of its codec
+func writeDefinition(buf *bytes.Buffer, def *baudot.VariantDefinition, name string) {
+	v := identifier(name, false) + "Definition"
+	buf.WriteString("import \"github.com/hsldymq/baudot\"\n\n")
+	fmt.Fprintf(buf, "var %s = baudot.VariantDefinition{\n", v)
+	fmt.Fprintf(buf, "Name: %q,\n", def.Name)
+	fmt.Fprintf(buf, "Registers: %#v,\n", def.Registers)
+	if len(def.Start) > 0 {
+		fmt.Fprintf(buf, "Start: %#v,\n", def.Start)
+	}
+	buf.WriteString("Codes: []baudot.CodeDefinition{\n")
+	for _, c := range def.Codes {
+		fmt.Fprintf(buf, "{Code: %d", c.Code)
+		if len(c.Chars) > 0 {
+			fmt.Fprintf(buf, ", Chars: %#v", c.Chars)
+		}
+		if len(c.Shift) > 0 {
+			fmt.Fprintf(buf, ", Shift: %#v", c.Shift)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("},\n")
+	if len(def.Aliases) > 0 {
+		buf.WriteString("Aliases: []baudot.AliasDefinition{\n")
+		for _, alias := range def.Aliases {
+			fmt.Fprintf(buf, "{Char: %q, Register: %q, Code: %d},\n", alias.Char, alias.Register, alias.Code)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "// New%s creates the codec of %s\n", name, def.Name)
+	fmt.Fprintf(buf, "func New%s(ignoreError bool) (baudot.Codec, error) {\nreturn baudot.NewVariant(%s, ignoreError)\n}\n", name, v)
+}
+
+func sortedCodes[V any](m map[byte]V) []byte {
+	codes := make([]byte, 0, len(m))
+	for code := range m {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	return codes
+}
+
+// firstCode orders the characters of the encoding table
+func firstCode(values []int8) int {
+	for charset, code := range values {
+		if code != -1 {
+			return charset*32 + int(code)
+		}
+	}
+
+	return len(values) * 32
+}
+
+// quote writes a rune literal the way the hand written tables do
+func quote(char rune) string {
+	switch {
+	case char == '\r':
+		return `'\r'`
+	case char == '\n':
+		return `'\n'`
+	case char == '\'':
+		return `'\''`
+	case char == '\\':
+		return `'\\'`
+	case unicode.IsPrint(char):
+		return "'" + string(char) + "'"
+	}
+
+	return fmt.Sprintf(`'\u%04X'`, char)
+}
+
+// identifier turns a name into a Go identifier, e.g. "US weather" into USWeather or uSWeather
+func identifier(name string, exported bool) string {
+	var b strings.Builder
+	upper := exported
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && !exported {
+			r = unicode.ToLower(r)
+		} else if upper {
+			r = unicode.ToUpper(r)
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
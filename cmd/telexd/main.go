@@ -0,0 +1,36 @@
+/*
+ * telexd runs a telex exchange for operator training(see baudot.NewExchange), e.g.
+ *
+ *	telexd -addr :2323 -first 1001
+ *
+ * Stations connect over TCP and send and receive one 5 bit code per byte.
+ */
+
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/hsldymq/baudot"
+)
+
+func main() {
+	addr := flag.String("addr", ":2323", "address to listen on")
+	first := flag.Int("first", 0, "number of the first subscriber, 1001 by default")
+	timeout := flag.Duration("answerback-timeout", time.Second, "how long to wait for the answerback of a station")
+	maxQueued := flag.Int("max-queued", 0, "codes queued for a station not reading before it's disconnected, 65536 by default")
+	flag.Parse()
+
+	exchange, err := baudot.NewExchange(baudot.ExchangeOptions{
+		FirstNumber:       *first,
+		AnswerbackTimeout: *timeout,
+		MaxQueued:         *maxQueued,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", *addr)
+	log.Fatal(exchange.ListenAndServe(*addr))
+}
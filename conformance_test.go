@@ -0,0 +1,79 @@
+package baudot_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hsldymq/baudot"
+	"github.com/hsldymq/baudot/baudottest"
+)
+
+var conformanceCodecs = []struct {
+	name     string
+	newCodec baudottest.NewCodec
+	options  baudottest.Options
+}{
+	{name: "ITA1", newCodec: func(ignoreError bool) baudot.Codec { return baudot.NewITA1(ignoreError) }},
+	{name: "ITA1 continental", newCodec: func(ignoreError bool) baudot.Codec { return baudot.NewITA1Continental(ignoreError) }},
+	{name: "ITA2", newCodec: func(ignoreError bool) baudot.Codec { return baudot.NewITA2(ignoreError) }},
+	{name: "USTTY", newCodec: func(ignoreError bool) baudot.Codec { return baudot.NewUSTTY(ignoreError) }},
+	{name: "Murray", newCodec: func(ignoreError bool) baudot.Codec { return baudot.NewMurray(ignoreError) }},
+	{name: "Weather", newCodec: func(ignoreError bool) baudot.Codec { return baudot.NewWeather(ignoreError) }},
+	{name: "ITA2 German", newCodec: func(ignoreError bool) baudot.Codec {
+		c, _ := baudot.NewITA2National(baudot.NationalGerman, ignoreError)
+		return c
+	}},
+	{name: "Katakana", newCodec: func(ignoreError bool) baudot.Codec { return baudot.NewKatakana(ignoreError) }, options: baudottest.Options{Normalize: decomposeKatakana}},
+}
+
+// decomposeKatakana writes voiced kana as the kana followed by the mark like they are sent
+var decomposeKatakana = func() func(string) string {
+	var pairs []string
+	for _, base := range "カキクケコサシスセソタチツテトハヒフヘホ" {
+		pairs = append(pairs, string(base+1), string(base)+"゛")
+	}
+	for _, base := range "ハヒフヘホ" {
+		pairs = append(pairs, string(base+2), string(base)+"゜")
+	}
+	pairs = append(pairs, "ヴ", "ウ゛")
+
+	return strings.NewReplacer(pairs...).Replace
+}()
+
+func TestConformance(t *testing.T) {
+	for _, c := range conformanceCodecs {
+		t.Run(c.name, func(t *testing.T) {
+			baudottest.Run(t, c.newCodec, c.options)
+		})
+	}
+}
+
+// conformanceCodec returns the codec of the suite with the name
+func conformanceCodec(tb testing.TB, name string) baudottest.NewCodec {
+	tb.Helper()
+
+	for _, c := range conformanceCodecs {
+		if c.name == name {
+			return c.newCodec
+		}
+	}
+	tb.Fatalf("no conformance codec %s", name)
+
+	return nil
+}
+
+func FuzzITA2Encode(f *testing.F) {
+	baudottest.FuzzEncode(f, conformanceCodec(f, "ITA2"))
+}
+
+func FuzzITA2Decode(f *testing.F) {
+	baudottest.FuzzDecode(f, conformanceCodec(f, "ITA2"))
+}
+
+func FuzzKatakanaEncode(f *testing.F) {
+	baudottest.FuzzEncode(f, conformanceCodec(f, "Katakana"))
+}
+
+func FuzzKatakanaDecode(f *testing.F) {
+	baudottest.FuzzDecode(f, conformanceCodec(f, "Katakana"))
+}
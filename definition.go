@@ -0,0 +1,425 @@
+/*
+ * Declarative variant definitions. A variant is a list of codes with, for each register, the character or control function on the code
+ * or the register the code shifts to. Definitions are JSON, e.g.
+ *
+ *	{
+ *		"name": "ITA2",
+ *		"registers": ["letters", "figures"],
+ *		"start": [0],
+ *		"codes": [
+ *			{"code": 0, "chars": ["NUL"]},
+ *			{"code": 1, "chars": ["E", "3"]},
+ *			{"code": 27, "shift": ["figures"]},
+ *			...
+ *		]
+ *	}
+ *
+ * A single entry in chars or shift applies to every register. Definitions are loaded at runtime with NewVariant/LoadVariant,
+ * or turned into Go tables by baudotgen(see cmd/baudotgen) with go generate. Both verify the tables.
+ */
+
+package baudot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type VariantDefinition struct {
+	Name string `json:"name"`
+	// Registers names the registers, the receiver starts in the first one
+	Registers []string `json:"registers"`
+	// Start are the codes a sequence begins with before its first shift code, e.g. ITA2's NULL
+	Start   []int             `json:"start,omitempty"`
+	Codes   []CodeDefinition  `json:"codes"`
+	Aliases []AliasDefinition `json:"aliases,omitempty"`
+}
+
+type CodeDefinition struct {
+	Code int `json:"code"`
+	// Chars in each register: a character, a control function(see ControlNames) or "" when the register doesn't have the code
+	Chars []string `json:"chars,omitempty"`
+	// Shift in each register: the name of the register the code shifts to, or "" when the code is not a shift code in the register
+	Shift []string `json:"shift,omitempty"`
+}
+
+// AliasDefinition is a character encoded like another one, e.g. a similar glyph, it's never decoded
+type AliasDefinition struct {
+	Char     string `json:"char"`
+	Register string `json:"register"`
+	Code     int    `json:"code"`
+}
+
+// ControlNames are the control functions a definition may put on a code
+var ControlNames = map[string]rune{
+	"NUL": '\u0000',
+	"SP":  ' ',
+	"CR":  '\r',
+	"LF":  '\n',
+	"BEL": '\u0007',
+	"WRU": WRU,
+}
+
+// VariantTables are the tables of a variant built from its definition
+type VariantTables struct {
+	Name string
+	// Registers names the registers, indexed by Charset
+	Registers []string
+	// Decoding maps the codes to the characters of each register, indexed by Charset
+	Decoding []map[byte]rune
+	// Encoding is the code of each character in each register, -1 if the register doesn't have it
+	Encoding map[rune][]int8
+	// Shifts maps the shift codes read in each register to the register they shift to, indexed by Charset
+	Shifts []map[byte]Charset
+	Start  []byte
+}
+
+type variant struct {
+	ignErr  bool
+	version version
+}
+
+// ParseVariant parses a JSON variant definition
+func ParseVariant(data []byte) (*VariantDefinition, error) {
+	var def VariantDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("Invalid definition: %v", err)
+	}
+
+	return &def, nil
+}
+
+// LoadVariant reads a JSON variant definition and creates its codec
+func LoadVariant(r io.Reader, ignoreError bool) (*variant, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	def, err := ParseVariant(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVariant(*def, ignoreError)
+}
+
+// NewVariant creates the codec of a variant definition, a definition with the name of a registered variant must have the same tables
+func NewVariant(def VariantDefinition, ignoreError bool) (*variant, error) {
+	t, err := def.tables()
+	if err != nil {
+		return nil, err
+	}
+	ver, err := registerTables(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return &variant{
+		ignErr:  ignoreError,
+		version: ver,
+	}, nil
+}
+
+// Definition returns the definition of the codec's variant
+func Definition(codec Codec) (*VariantDefinition, error) {
+	v, ok := codec.(versioned)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported codec: %T", codec)
+	}
+	t, err := lookupTables(v.ver())
+	if err != nil {
+		return nil, err
+	}
+
+	return t.definition(), nil
+}
+
+// Tables builds and verifies the tables of the definition without registering the variant, baudotgen generates Go tables from them
+func (def VariantDefinition) Tables() (*VariantTables, error) {
+	t, err := def.tables()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.verify(); err != nil {
+		return nil, err
+	}
+
+	return &VariantTables{
+		Name:      t.name,
+		Registers: t.names,
+		Decoding:  t.registers,
+		Encoding:  t.charmap,
+		Shifts:    t.shifts,
+		Start:     t.start,
+	}, nil
+}
+
+// tables builds the tables of the definition
+func (def VariantDefinition) tables() (*tables, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("Invalid definition: no name")
+	}
+	if len(def.Registers) == 0 {
+		return nil, fmt.Errorf("Invalid definition %s: no register", def.Name)
+	}
+
+	charsets := map[string]Charset{}
+	for i, name := range def.Registers {
+		if _, ok := charsets[name]; ok || name == "" {
+			return nil, fmt.Errorf("Invalid definition %s: register %q", def.Name, name)
+		}
+		charsets[name] = Charset(i)
+	}
+
+	t := &tables{
+		name:    def.Name,
+		names:   append([]string{}, def.Registers...),
+		charmap: map[rune][]int8{},
+	}
+	for range def.Registers {
+		t.registers = append(t.registers, map[byte]rune{})
+		t.shifts = append(t.shifts, map[byte]Charset{})
+	}
+	for _, code := range def.Start {
+		if code < 0 || code >= 32 {
+			return nil, fmt.Errorf("Invalid definition %s: start code %d", def.Name, code)
+		}
+		t.start = append(t.start, byte(code))
+	}
+
+	// spread returns the entries of each register, a single entry applies to every register
+	spread := func(entries []string, code int) ([]string, error) {
+		switch len(entries) {
+		case 0:
+			return make([]string, len(def.Registers)), nil
+		case 1:
+			all := make([]string, len(def.Registers))
+			for i := range all {
+				all[i] = entries[0]
+			}
+			return all, nil
+		case len(def.Registers):
+			return entries, nil
+		}
+		return nil, fmt.Errorf("Invalid definition %s: code %d has %d entries for %d registers", def.Name, code, len(entries), len(def.Registers))
+	}
+
+	seen := map[int]bool{}
+	for _, c := range def.Codes {
+		if c.Code < 0 || c.Code >= 32 || seen[c.Code] {
+			return nil, fmt.Errorf("Invalid definition %s: code %d", def.Name, c.Code)
+		}
+		seen[c.Code] = true
+
+		chars, err := spread(c.Chars, c.Code)
+		if err != nil {
+			return nil, err
+		}
+		shifts, err := spread(c.Shift, c.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		for charset := range def.Registers {
+			if chars[charset] != "" && shifts[charset] != "" {
+				return nil, fmt.Errorf("Invalid definition %s: code %d is both a character and a shift code in %s", def.Name, c.Code, def.Registers[charset])
+			}
+			if shifts[charset] != "" {
+				to, ok := charsets[shifts[charset]]
+				if !ok {
+					return nil, fmt.Errorf("Invalid definition %s: code %d shifts to unknown register %q", def.Name, c.Code, shifts[charset])
+				}
+				t.shifts[charset][byte(c.Code)] = to
+			}
+			if chars[charset] != "" {
+				char, err := parseChar(chars[charset])
+				if err != nil {
+					return nil, fmt.Errorf("Invalid definition %s: code %d: %v", def.Name, c.Code, err)
+				}
+				t.registers[charset][byte(c.Code)] = char
+			}
+		}
+	}
+	t.charmap = charmapOf(t.registers)
+
+	for _, alias := range def.Aliases {
+		char, err := parseChar(alias.Char)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid definition %s: alias: %v", def.Name, err)
+		}
+		charset, ok := charsets[alias.Register]
+		if !ok {
+			return nil, fmt.Errorf("Invalid definition %s: alias %c in unknown register %q", def.Name, char, alias.Register)
+		}
+		if alias.Code < 0 || alias.Code >= 32 {
+			return nil, fmt.Errorf("Invalid definition %s: alias %c on code %d", def.Name, char, alias.Code)
+		}
+
+		values, ok := t.charmap[char]
+		if !ok {
+			values = make([]int8, len(t.registers))
+			for i := range values {
+				values[i] = -1
+			}
+		}
+		values[charset] = int8(alias.Code)
+		t.charmap[char] = values
+	}
+
+	return t, nil
+}
+
+// parseChar parses a character of a definition, a single character or the name of a control function
+func parseChar(s string) (rune, error) {
+	if char, ok := ControlNames[s]; ok {
+		return char, nil
+	}
+	if runes := []rune(s); len(runes) == 1 {
+		return runes[0], nil
+	}
+
+	return 0, fmt.Errorf("Invalid Char: %q", s)
+}
+
+// formatChar is the inverse of parseChar
+func formatChar(char rune) string {
+	names := make([]string, 0, len(ControlNames))
+	for name := range ControlNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ControlNames[name] == char {
+			return name
+		}
+	}
+
+	return string(char)
+}
+
+// definition describes the tables as a definition, entries the same in every register are written once
+func (t *tables) definition() *VariantDefinition {
+	def := &VariantDefinition{
+		Name:      t.name,
+		Registers: append([]string{}, t.names...),
+	}
+	for _, code := range t.start {
+		def.Start = append(def.Start, int(code))
+	}
+
+	collapse := func(entries []string) []string {
+		empty := true
+		for _, entry := range entries {
+			if entry != entries[0] {
+				return entries
+			}
+			empty = empty && entry == ""
+		}
+		if empty {
+			return nil
+		}
+		return entries[:1]
+	}
+
+	for code := 0; code < 32; code++ {
+		chars := make([]string, len(t.registers))
+		shifts := make([]string, len(t.registers))
+		for charset, register := range t.registers {
+			if char, ok := register[byte(code)]; ok {
+				chars[charset] = formatChar(char)
+			}
+			if to, ok := t.shifts[charset][byte(code)]; ok {
+				shifts[charset] = t.names[to]
+			}
+		}
+
+		c := CodeDefinition{Code: code, Chars: collapse(chars), Shift: collapse(shifts)}
+		if c.Chars != nil || c.Shift != nil {
+			def.Codes = append(def.Codes, c)
+		}
+	}
+
+	// characters which are never decoded are aliases
+	decoded := map[rune]bool{}
+	for _, register := range t.registers {
+		for _, char := range register {
+			decoded[char] = true
+		}
+	}
+	for char, values := range t.charmap {
+		if decoded[char] {
+			continue
+		}
+		for charset, code := range values {
+			if code != -1 {
+				def.Aliases = append(def.Aliases, AliasDefinition{Char: formatChar(char), Register: t.names[charset], Code: int(code)})
+			}
+		}
+	}
+	sort.Slice(def.Aliases, func(i, j int) bool {
+		a, b := def.Aliases[i], def.Aliases[j]
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return strings.Compare(a.Char, b.Char) < 0
+	})
+
+	return def
+}
+
+// Name returns the name of the variant
+func (c *variant) Name() string {
+	t, err := lookupTables(c.version)
+	if err != nil {
+		return ""
+	}
+
+	return t.name
+}
+
+// Encode string into byte array represent the sequence of codes of the variant
+func (c *variant) Encode(msg string) ([]byte, error) {
+	return encode(msg, c.ignErr, c.version)
+}
+
+// Decode codes of the variant to string
+func (c *variant) Decode(codes []byte) (string, error) {
+	return decode(codes, c.ignErr, c.version)
+}
+
+// EncodeChar encodes a character, returns the register the receiver is in after the code
+func (c *variant) EncodeChar(char rune, currentCharset Charset) (byte, Charset, error) {
+	return encodeChar(char, currentCharset, c.version)
+}
+
+// DecodeChar decodes a code to rune, returns the register the receiver is in after the code
+func (c *variant) DecodeChar(code byte, currentCharset Charset) (rune, Charset, error) {
+	return decodeChar(code, currentCharset, c.version)
+}
+
+func (c *variant) ver() version {
+	return c.version
+}
+
+// Repertoire returns the characters of a register in code order
+func (c *variant) Repertoire(charset Charset) []rune {
+	return repertoire(c.version, charset)
+}
+
+// CanEncode reports whether every character of msg is in the variant
+func (c *variant) CanEncode(msg string) bool {
+	return len(c.Unsupported(msg)) == 0
+}
+
+// Unsupported returns the characters of msg which are not in the variant
+func (c *variant) Unsupported(msg string) []UnsupportedChar {
+	return unsupported(msg, c.version, identity)
+}
+
+// EncodedLen returns the number of codes Encode returns for msg, shift codes included
+func (c *variant) EncodedLen(msg string) (int, error) {
+	return encodedLen(msg, c.ignErr, c.version)
+}
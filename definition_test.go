@@ -0,0 +1,238 @@
+package baudot
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefinitionRoundTrip(t *testing.T) {
+	tt := []struct {
+		caseName string
+		codec    Codec
+		msg      string
+	}{
+		{caseName: "test ITA1", codec: NewITA1(false), msg: "HELLO 123"},
+		{caseName: "test ITA2", codec: NewITA2(false), msg: "RYRY 1/2\r\n"},
+		{caseName: "test US TTY", codec: NewUSTTY(false), msg: "HELLO $5"},
+		{caseName: "test Murray", codec: NewMurray(false), msg: "SAY ½\r\n"},
+		{caseName: "test weather", codec: NewWeather(false), msg: "WIND ↑"},
+		{caseName: "test katakana", codec: NewKatakana(false), msg: "ア1ヒB"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			def, err := Definition(tc.codec)
+			if err != nil {
+				t.Fatalf("expect a definition, got %v", err)
+			}
+			c, err := NewVariant(*def, false)
+			if err != nil {
+				t.Fatalf("expect the definition loaded, got %v", err)
+			}
+
+			expect, _ := tc.codec.Encode(tc.msg)
+			codes, err := c.Encode(tc.msg)
+			if err != nil || string(codes) != string(expect) {
+				t.Errorf("expect %v, got %v, %v", expect, codes, err)
+			}
+			str, err := c.Decode(codes)
+			if err != nil || str != tc.msg {
+				t.Errorf("expect %q, got %q, %v", tc.msg, str, err)
+			}
+		})
+	}
+}
+
+func TestLoadVariant(t *testing.T) {
+	f, err := os.Open("variants/murray.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c, err := LoadVariant(f, false)
+	if err != nil {
+		t.Fatalf("expect Murray loaded, got %v", err)
+	}
+	if c.Name() != "Murray" {
+		t.Errorf("expect Murray, got %s", c.Name())
+	}
+
+	msg := "SAY ½\r\nTO 3/4"
+	expect, _ := NewMurray(false).Encode(msg)
+	codes, err := c.Encode(msg)
+	if err != nil || string(codes) != string(expect) {
+		t.Errorf("expect %v, got %v, %v", expect, codes, err)
+	}
+
+	again, err := LoadVariant(strings.NewReader(`{"name": "Murray"`), false)
+	if err == nil {
+		t.Errorf("expect an error for a truncated definition, got %v", again)
+	}
+}
+
+func TestNewVariant(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		def        string
+		msg        string
+		expect     []byte
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName: "test three registers",
+			def: `{"name": "Test three registers", "registers": ["a", "b", "c"], "codes": [
+				{"code": 1, "chars": ["A", "B", "C"]},
+				{"code": 2, "chars": ["SP"]},
+				{"code": 29, "shift": ["c"]},
+				{"code": 30, "shift": ["b"]},
+				{"code": 31, "shift": ["a"]}]}`,
+			msg:        "AB C",
+			expect:     []byte{31, 1, 30, 1, 2, 29, 1},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{31, 1, 30, 1, 2, 29, 1}),
+		},
+		{
+			caseName: "test alias",
+			def: `{"name": "Test alias", "registers": ["letters", "figures"], "codes": [
+				{"code": 1, "chars": ["O", "0"]},
+				{"code": 27, "shift": ["figures"]},
+				{"code": 31, "shift": ["letters"]}],
+				"aliases": [{"char": "Ø", "register": "letters", "code": 1}]}`,
+			msg:        "ØO",
+			expect:     []byte{31, 1, 1},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{31, 1, 1}),
+		},
+		{
+			caseName:   "test no name",
+			def:        `{"registers": ["letters"], "codes": [{"code": 1, "chars": ["A"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test code out of range",
+			def:        `{"name": "Test", "registers": ["letters"], "codes": [{"code": 32, "chars": ["A"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test duplicate code",
+			def:        `{"name": "Test", "registers": ["letters"], "codes": [{"code": 1, "chars": ["A"]}, {"code": 1, "chars": ["B"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test entries for each register",
+			def:        `{"name": "Test", "registers": ["a", "b", "c"], "codes": [{"code": 1, "chars": ["A", "B"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test unknown register",
+			def:        `{"name": "Test", "registers": ["letters"], "codes": [{"code": 1, "chars": ["A"]}, {"code": 27, "shift": ["figures"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test char and shift",
+			def:        `{"name": "Test", "registers": ["letters"], "codes": [{"code": 1, "chars": ["A"], "shift": ["letters"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test invalid char",
+			def:        `{"name": "Test", "registers": ["letters"], "codes": [{"code": 1, "chars": ["AB"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test ambiguous char",
+			def:        `{"name": "Test", "registers": ["letters"], "codes": [{"code": 1, "chars": ["A"]}, {"code": 2, "chars": ["A"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test name of another variant",
+			def:        `{"name": "ITA2", "registers": ["letters"], "codes": [{"code": 1, "chars": ["A"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName: "test unreachable register",
+			def: `{"name": "Test", "registers": ["letters", "figures"], "codes": [
+				{"code": 1, "chars": ["A", "1"]}]}`,
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			def, err := ParseVariant([]byte(tc.def))
+			if err != nil {
+				t.Fatalf("expect the definition parsed, got %v", err)
+			}
+			c, err := NewVariant(*def, false)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, err)
+				}
+				return
+			}
+			if tc.shouldFail {
+				t.Fatalf(tc.failedText, c)
+			}
+
+			codes, err := c.Encode(tc.msg)
+			if err != nil || string(codes) != string(tc.expect) {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+			}
+		})
+	}
+}
+
+func TestVariantTables(t *testing.T) {
+	data, err := os.ReadFile("variants/murray.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := ParseVariant(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tables baudotgen writes are the built-in ones
+	tables, err := def.Tables()
+	if err != nil {
+		t.Fatalf("expect the tables of Murray, got %v", err)
+	}
+	builtin := murrayTables()
+	if tables.Name != builtin.name || !reflect.DeepEqual(tables.Decoding, builtin.registers) || !reflect.DeepEqual(tables.Encoding, builtin.charmap) || !reflect.DeepEqual(tables.Shifts, builtin.shifts) {
+		t.Errorf("expect the tables of the built-in Murray, got %v", tables)
+	}
+
+	// tables are verified but not registered, so a changed built-in variant can be generated again
+	def.Codes[0].Chars = []string{"NUL", "#"}
+	if _, err := def.Tables(); err != nil {
+		t.Errorf("expect the tables of a changed Murray, got %v", err)
+	}
+	def.Codes[0].Chars = []string{"NUL", "3"}
+	if _, err := def.Tables(); err == nil {
+		t.Errorf("expect an error for 3 on 2 codes")
+	}
+}
+
+func TestNewVariantShared(t *testing.T) {
+	def, _ := Definition(NewITA2(false))
+	a, err := NewVariant(*def, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewVariant(*def, true)
+	if a.ver() != b.ver() {
+		t.Errorf("expect the same version, got %d and %d", a.ver(), b.ver())
+	}
+}
@@ -0,0 +1,582 @@
+/*
+ * Telex exchange simulator for operator training. Subscribers connect over TCP and send and receive one code per byte,
+ * LineClear(outside the 5 bit codes) clears a call like the line polarity reversal of a real line.
+ *
+ * On connection the exchange sends WRU and keeps the answerback as the station's identity, a station answering with the answerback
+ * of an absent subscriber gets its number back along with the messages stored for it, any other station gets a new number.
+ * The exchange then prints the number and GA(go ahead) and reads a keyboard selection ending with + or a new line:
+ *
+ *	1002+        call 1002
+ *	1002.1003+   conference call with 1002 and 1003
+ *	/1002.1003+  store a message for 1002 and 1003, the message ends with NNNN and is delivered when they are free
+ *
+ * The selection and the service signals are in the codec of the exchange, the traffic of a call is passed through untouched,
+ * so subscribers may use any variant between them. When a call is set up each party receives the answerback of the other.
+ * Service signals: OCC(busy), NP(no such number), ABS(absent), NA(selection not admitted), MSG(send the message), STORED.
+ */
+
+package baudot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LineClear clears the call, it's sent by the subscriber clearing and by the exchange to the other parties
+const LineClear byte = 0x80
+
+const (
+	defaultFirstNumber       = 1001
+	defaultAnswerbackTimeout = time.Second
+	// longest answerback kept, 20 characters with their shift codes
+	maxAnswerbackCodes = 48
+	maxSelectionLen    = 64
+	defaultMaxQueued   = 64 << 10
+)
+
+type ExchangeOptions struct {
+	// Codec reads the selections and writes the service signals, ITA2 by default
+	Codec Codec
+	// FirstNumber is the number of the first subscriber, 0 means 1001
+	FirstNumber int
+	// AnswerbackTimeout is how long the exchange waits for the answerback, 0 means 1 second
+	AnswerbackTimeout time.Duration
+	// MaxQueued is the number of codes queued for a station not reading them, past it the station is disconnected, 0 means 65536
+	MaxQueued int
+}
+
+// Subscriber describes a subscriber of the exchange
+type Subscriber struct {
+	Number     int
+	Answerback string
+	// Present is false once the station disconnected
+	Present bool
+	// Busy is true during a call or while storing a message
+	Busy bool
+}
+
+type lineState byte
+
+const (
+	// reading a selection
+	lineIdle lineState = 0
+	lineCall lineState = 1
+	// reading a message to store
+	lineStore lineState = 2
+)
+
+type exchange struct {
+	ver     version
+	codec   Codec
+	options ExchangeOptions
+	wru     []byte
+
+	mu          sync.Mutex
+	subscribers map[int]*subscriber
+	next        int
+	store       map[int][]storedMessage
+	listeners   []net.Listener
+	closed      bool
+}
+
+type subscriber struct {
+	number     int
+	answerback []byte
+	present    bool
+	conn       net.Conn
+	out        *outbox
+
+	state   lineState
+	call    *call
+	charset Charset
+	// selection read so far
+	selection []rune
+	// message being stored, storeStarts is the index in storeCodes of each character of storeText
+	storeTo     []int
+	storeCodes  []byte
+	storeText   []rune
+	storeStarts []int
+}
+
+type call struct {
+	parties []*subscriber
+}
+
+type storedMessage struct {
+	from  int
+	codes []byte
+}
+
+// outbox queues the codes sent to a subscriber, so the exchange never waits for a slow line,
+// a line falling behind by more than max codes is closed
+type outbox struct {
+	conn   net.Conn
+	max    int
+	mu     sync.Mutex
+	cond   *sync.Cond
+	data   []byte
+	closed bool
+}
+
+// NewExchange creates a telex exchange
+func NewExchange(options ExchangeOptions) (*exchange, error) {
+	if options.Codec == nil {
+		options.Codec = NewITA2(true)
+	}
+	if options.FirstNumber <= 0 {
+		options.FirstNumber = defaultFirstNumber
+	}
+	if options.AnswerbackTimeout <= 0 {
+		options.AnswerbackTimeout = defaultAnswerbackTimeout
+	}
+	if options.MaxQueued <= 0 {
+		options.MaxQueued = defaultMaxQueued
+	}
+	v, ok := options.Codec.(versioned)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported codec: %T", options.Codec)
+	}
+	ab, err := NewAnswerback(options.Codec, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &exchange{
+		ver:         v.ver(),
+		codec:       options.Codec,
+		options:     options,
+		wru:         ab.WRU(),
+		subscribers: map[int]*subscriber{},
+		next:        options.FirstNumber,
+		store:       map[int][]storedMessage{},
+	}, nil
+}
+
+// ListenAndServe listens on the TCP address and serves the stations connecting
+func (e *exchange) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return e.Serve(l)
+}
+
+// Serve serves the stations connecting to the listener until the exchange is closed
+func (e *exchange) Serve(l net.Listener) error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		l.Close()
+		return fmt.Errorf("Exchange closed")
+	}
+	e.listeners = append(e.listeners, l)
+	e.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			e.mu.Lock()
+			closed := e.closed
+			e.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go e.ServeConn(conn)
+	}
+}
+
+// ServeConn serves a station until it disconnects
+func (e *exchange) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+
+	out := newOutbox(conn, e.options.MaxQueued)
+	done := make(chan struct{})
+	go func() {
+		out.run()
+		close(done)
+	}()
+	defer func() {
+		out.close()
+		<-done
+	}()
+
+	out.push(e.wru)
+	answerback, err := e.readAnswerback(conn)
+	if err != nil {
+		return err
+	}
+	sub, err := e.connect(conn, out, answerback)
+	if err != nil {
+		return err
+	}
+	defer e.disconnect(sub)
+
+	buf := make([]byte, 512)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			e.receive(sub, buf[:n])
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// Subscribers lists the subscribers by number
+func (e *exchange) Subscribers() []Subscriber {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var subscribers []Subscriber
+	for _, sub := range e.subscribers {
+		answerback, _ := decode(sub.answerback, true, e.ver)
+		subscribers = append(subscribers, Subscriber{
+			Number:     sub.number,
+			Answerback: strings.Trim(answerback, " \r\n"),
+			Present:    sub.present,
+			Busy:       sub.state != lineIdle,
+		})
+	}
+	sort.Slice(subscribers, func(i, j int) bool { return subscribers[i].Number < subscribers[j].Number })
+
+	return subscribers
+}
+
+// Close stops serving and disconnects every station
+func (e *exchange) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.closed = true
+	for _, l := range e.listeners {
+		l.Close()
+	}
+	for _, sub := range e.subscribers {
+		if sub.present {
+			sub.conn.Close()
+		}
+	}
+
+	return nil
+}
+
+// readAnswerback reads the codes the station sends until it's silent for AnswerbackTimeout
+func (e *exchange) readAnswerback(conn net.Conn) ([]byte, error) {
+	var answerback []byte
+	buf := make([]byte, maxAnswerbackCodes)
+	for len(answerback) < maxAnswerbackCodes {
+		conn.SetReadDeadline(time.Now().Add(e.options.AnswerbackTimeout))
+		n, err := conn.Read(buf[:maxAnswerbackCodes-len(answerback)])
+		answerback = append(answerback, buf[:n]...)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	return answerback, nil
+}
+
+// connect registers the station, under the number of the absent subscriber having its answerback or a new number
+func (e *exchange) connect(conn net.Conn, out *outbox, answerback []byte) (*subscriber, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil, fmt.Errorf("Exchange closed")
+	}
+
+	var sub *subscriber
+	if len(answerback) > 0 {
+		for _, s := range e.subscribers {
+			if !s.present && bytes.Equal(s.answerback, answerback) && (sub == nil || s.number < sub.number) {
+				sub = s
+			}
+		}
+	}
+	if sub == nil {
+		sub = &subscriber{number: e.next, answerback: answerback}
+		e.subscribers[sub.number] = sub
+		e.next++
+	}
+	sub.present = true
+	sub.conn = conn
+	sub.out = out
+	sub.state = lineIdle
+	sub.charset = Letters
+	sub.selection = nil
+
+	e.signal(sub, fmt.Sprintf("%d GA", sub.number))
+	e.deliver(sub)
+
+	return sub, nil
+}
+
+func (e *exchange) disconnect(sub *subscriber) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// absent before clearing its call, so its stored messages wait for its return
+	sub.present = false
+	e.clear(sub)
+	sub.conn = nil
+	sub.out = nil
+}
+
+// receive handles the codes sent by a subscriber
+func (e *exchange) receive(sub *subscriber, codes []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, code := range codes {
+		if code == LineClear {
+			e.clear(sub)
+			continue
+		}
+		if code >= 32 {
+			// unknown line signal
+			continue
+		}
+
+		// follow the register of the subscriber's terminal in every state, so a selection after a call is read right
+		char, shiftedCharset, err := decodeChar(code, sub.charset, e.ver)
+		isChar := err == nil && shiftedCharset == sub.charset && char != '\u0000'
+		sub.charset = shiftedCharset
+
+		switch sub.state {
+		case lineCall:
+			for _, p := range sub.call.parties {
+				if p != sub {
+					p.out.push([]byte{code})
+				}
+			}
+		case lineStore:
+			sub.storeCodes = append(sub.storeCodes, code)
+			if isChar {
+				sub.storeText = append(sub.storeText, char)
+				sub.storeStarts = append(sub.storeStarts, len(sub.storeCodes)-1)
+				e.storeEnd(sub)
+			}
+		case lineIdle:
+			if !isChar {
+				continue
+			}
+			if char == '\r' || char == '\n' || char == '+' {
+				selection := strings.TrimSpace(string(sub.selection))
+				sub.selection = nil
+				if selection != "" {
+					e.selectNumbers(sub, selection)
+				}
+			} else if len(sub.selection) < maxSelectionLen {
+				sub.selection = append(sub.selection, char)
+			}
+		}
+	}
+}
+
+// selectNumbers sets up the call or starts storing the message selected
+func (e *exchange) selectNumbers(sub *subscriber, selection string) {
+	store := strings.HasPrefix(selection, "/")
+	var numbers []int
+	for _, part := range strings.Split(strings.TrimPrefix(selection, "/"), ".") {
+		number, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || number == sub.number {
+			e.signal(sub, "NA")
+			return
+		}
+		numbers = append(numbers, number)
+	}
+
+	if store {
+		for _, number := range numbers {
+			if _, ok := e.subscribers[number]; !ok {
+				e.signal(sub, fmt.Sprintf("%d NP", number))
+				return
+			}
+		}
+		sub.state = lineStore
+		sub.storeTo = numbers
+		e.signal(sub, "MSG")
+		return
+	}
+
+	var called []*subscriber
+	for _, number := range numbers {
+		target, ok := e.subscribers[number]
+		switch {
+		case !ok:
+			e.signal(sub, fmt.Sprintf("%d NP", number))
+		case !target.present:
+			e.signal(sub, fmt.Sprintf("%d ABS", number))
+		case target.state != lineIdle:
+			e.signal(sub, fmt.Sprintf("%d OCC", number))
+		default:
+			called = append(called, target)
+		}
+	}
+	if len(called) == 0 {
+		return
+	}
+
+	c := &call{parties: append([]*subscriber{sub}, called...)}
+	for _, p := range c.parties {
+		p.state = lineCall
+		p.call = c
+		p.selection = nil
+	}
+	for _, p := range called {
+		sub.out.push(p.answerback)
+		p.out.push(sub.answerback)
+	}
+}
+
+// storeEnd stores the message when its last characters are NNNN
+func (e *exchange) storeEnd(sub *subscriber) {
+	n := len(sub.storeText)
+	if n < 4 || string(sub.storeText[n-4:]) != "NNNN" {
+		return
+	}
+
+	codes := append([]byte{}, sub.storeCodes[:sub.storeStarts[n-4]]...)
+	to := sub.storeTo
+	sub.state = lineIdle
+	sub.storeTo, sub.storeCodes, sub.storeText, sub.storeStarts = nil, nil, nil, nil
+	for _, number := range to {
+		e.store[number] = append(e.store[number], storedMessage{from: sub.number, codes: codes})
+	}
+	e.signal(sub, "STORED")
+
+	for _, number := range to {
+		e.deliver(e.subscribers[number])
+	}
+	e.deliver(sub)
+}
+
+// clear takes the subscriber out of its call or drops the message it's storing,
+// the call is cleared for everyone when a single party is left
+func (e *exchange) clear(sub *subscriber) {
+	switch sub.state {
+	case lineStore:
+		sub.storeTo, sub.storeCodes, sub.storeText, sub.storeStarts = nil, nil, nil, nil
+	case lineCall:
+		c := sub.call
+		for i, p := range c.parties {
+			if p == sub {
+				c.parties = append(c.parties[:i], c.parties[i+1:]...)
+				break
+			}
+		}
+		if len(c.parties) == 1 {
+			last := c.parties[0]
+			c.parties = nil
+			last.state = lineIdle
+			last.call = nil
+			last.out.push([]byte{LineClear})
+			e.deliver(last)
+		}
+	default:
+		return
+	}
+
+	sub.state = lineIdle
+	sub.call = nil
+	if sub.present {
+		e.deliver(sub)
+	}
+}
+
+// deliver sends the stored messages to a free subscriber
+func (e *exchange) deliver(sub *subscriber) {
+	if sub == nil || !sub.present || sub.state != lineIdle {
+		return
+	}
+
+	for _, msg := range e.store[sub.number] {
+		e.signal(sub, fmt.Sprintf("MSG %d", msg.from))
+		sub.out.push(msg.codes)
+		e.signal(sub, "NNNN")
+	}
+	delete(e.store, sub.number)
+}
+
+// signal sends a service signal on a new line
+func (e *exchange) signal(sub *subscriber, text string) {
+	codes, _ := encode("\r\n"+text+"\r\n", true, e.ver)
+	sub.out.push(codes)
+}
+
+func newOutbox(conn net.Conn, max int) *outbox {
+	o := &outbox{conn: conn, max: max}
+	o.cond = sync.NewCond(&o.mu)
+
+	return o
+}
+
+func (o *outbox) push(codes []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return
+	}
+	if len(o.data)+len(codes) > o.max {
+		// the station stopped reading, closing the line ends its serving
+		o.data = nil
+		o.closed = true
+		o.conn.Close()
+	} else {
+		o.data = append(o.data, codes...)
+	}
+	o.cond.Signal()
+}
+
+func (o *outbox) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.closed = true
+	o.cond.Signal()
+}
+
+// run writes the queued codes until the outbox is closed and empty
+func (o *outbox) run() {
+	for {
+		o.mu.Lock()
+		for len(o.data) == 0 && !o.closed {
+			o.cond.Wait()
+		}
+		data := o.data
+		o.data = nil
+		closed := o.closed
+		o.mu.Unlock()
+
+		if len(data) > 0 {
+			if _, err := o.conn.Write(data); err != nil {
+				o.conn.Close()
+				return
+			}
+		}
+		if closed && len(data) == 0 {
+			return
+		}
+	}
+}
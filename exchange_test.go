@@ -0,0 +1,298 @@
+package baudot
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type telexStation struct {
+	t        *testing.T
+	conn     net.Conn
+	received []byte
+	// where the next expect starts in the decoded text and in the codes
+	textOffset int
+	codeOffset int
+}
+
+func newTestExchange(t *testing.T) (*exchange, string) {
+	t.Helper()
+
+	return serveTestExchange(t, ExchangeOptions{AnswerbackTimeout: 50 * time.Millisecond})
+}
+
+func serveTestExchange(t *testing.T, options ExchangeOptions) (*exchange, string) {
+	t.Helper()
+
+	e, err := NewExchange(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go e.Serve(l)
+	t.Cleanup(func() { e.Close() })
+
+	return e, l.Addr().String()
+}
+
+// dialStation connects a station answering WRU with its answerback and waits for its number
+func dialStation(t *testing.T, addr string, answerback string, number string) *telexStation {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	s := &telexStation{t: t, conn: conn}
+
+	ab, err := NewAnswerback(NewITA2(false), answerback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.waitFor(func() bool {
+		_, ok := ab.Receive(s.received)
+		return ok
+	}, "WRU")
+	s.received = nil
+	if answerback != "" {
+		s.write(ab.Codes())
+	}
+	s.expect(number + " GA")
+
+	return s
+}
+
+func (s *telexStation) write(codes []byte) {
+	s.t.Helper()
+
+	if _, err := s.conn.Write(codes); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func (s *telexStation) send(text string) {
+	s.t.Helper()
+
+	codes, err := NewITA2(false).Encode(text)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.write(codes)
+}
+
+// waitFor reads until done returns true
+func (s *telexStation) waitFor(done func() bool, what string) {
+	s.t.Helper()
+
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		s.conn.SetReadDeadline(deadline)
+		n, err := s.conn.Read(buf)
+		s.received = append(s.received, buf[:n]...)
+		if err != nil && !done() {
+			text, _ := NewITA2(true).Decode(s.received)
+			s.t.Fatalf("expect %s, got %q(%v), %v", what, text, s.received, err)
+		}
+	}
+}
+
+// expect reads until the text is received after the previous one
+func (s *telexStation) expect(text string) {
+	s.t.Helper()
+
+	s.waitFor(func() bool {
+		received, _ := NewITA2(true).Decode(s.received)
+		i := strings.Index(received[s.textOffset:], text)
+		if i < 0 {
+			return false
+		}
+		s.textOffset += i + len(text)
+		return true
+	}, text)
+}
+
+// expectCodes reads until the codes are received after the previous ones
+func (s *telexStation) expectCodes(codes []byte) {
+	s.t.Helper()
+
+	s.waitFor(func() bool {
+		i := bytes.Index(s.received[s.codeOffset:], codes)
+		if i < 0 {
+			return false
+		}
+		s.codeOffset += i + len(codes)
+		return true
+	}, "codes")
+}
+
+func TestExchangeCall(t *testing.T) {
+	e, addr := newTestExchange(t)
+	a := dialStation(t, addr, "1001 ALPHA", "1001")
+	b := dialStation(t, addr, "1002 BRAVO", "1002")
+
+	a.send("1002+")
+	a.expect("1002 BRAVO")
+	b.expect("1001 ALPHA")
+
+	// traffic is passed through untouched, whatever the variant
+	traffic := []byte{2, 30, 0, 31, 0, 27, 4, 1}
+	a.write(traffic)
+	b.expectCodes(traffic)
+	b.send("OK")
+	a.expect("OK")
+
+	if subscribers := e.Subscribers(); len(subscribers) != 2 || !subscribers[0].Busy || subscribers[1].Answerback != "1002 BRAVO" {
+		t.Errorf("expect 2 busy subscribers, got %v", subscribers)
+	}
+
+	a.write([]byte{LineClear})
+	b.expectCodes([]byte{LineClear})
+
+	// both are free again
+	b.send("1001+")
+	b.expect("1001 ALPHA")
+	a.expect("1002 BRAVO")
+}
+
+func TestExchangeServiceSignals(t *testing.T) {
+	_, addr := newTestExchange(t)
+	a := dialStation(t, addr, "ALPHA", "1001")
+	b := dialStation(t, addr, "BRAVO", "1002")
+	c := dialStation(t, addr, "CHARLIE", "1003")
+
+	tt := []struct {
+		caseName  string
+		selection string
+		expect    string
+	}{
+		{caseName: "test unobtainable", selection: "1099+", expect: "1099 NP"},
+		{caseName: "test not admitted", selection: "1X+", expect: "NA"},
+		{caseName: "test own number", selection: "1003+", expect: "NA"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			c.send(tc.selection)
+			c.expect(tc.expect)
+		})
+	}
+
+	a.send("1002\r\n")
+	a.expect("BRAVO")
+	b.expect("ALPHA")
+	c.send("1001+")
+	c.expect("1001 OCC")
+
+	b.conn.Close()
+	a.expectCodes([]byte{LineClear})
+	c.send("1002+")
+	c.expect("1002 ABS")
+}
+
+func TestExchangeConference(t *testing.T) {
+	_, addr := newTestExchange(t)
+	a := dialStation(t, addr, "ALPHA", "1001")
+	b := dialStation(t, addr, "BRAVO", "1002")
+	c := dialStation(t, addr, "CHARLIE", "1003")
+
+	a.send("1002.1003+")
+	a.expect("BRAVO")
+	a.expect("CHARLIE")
+	b.expect("ALPHA")
+	c.expect("ALPHA")
+
+	b.send("HELLO")
+	a.expect("HELLO")
+	c.expect("HELLO")
+
+	// the call goes on without the party leaving
+	b.write([]byte{LineClear})
+	c.send("BYE")
+	a.expect("BYE")
+	a.write([]byte{LineClear})
+	c.expectCodes([]byte{LineClear})
+}
+
+func TestExchangeStoreAndForward(t *testing.T) {
+	e, addr := newTestExchange(t)
+	a := dialStation(t, addr, "ALPHA", "1001")
+	b := dialStation(t, addr, "BRAVO", "1002")
+	c := dialStation(t, addr, "CHARLIE", "1003")
+
+	// 1002 is busy, the message waits for the end of the call
+	b.send("1003+")
+	b.expect("CHARLIE")
+	c.expect("BRAVO")
+
+	a.send("/1002+")
+	a.expect("MSG")
+	a.send("MEET AT 10\r\nNNNN")
+	a.expect("STORED")
+
+	b.write([]byte{LineClear})
+	b.expect("MSG 1001")
+	b.expect("MEET AT 10")
+
+	// an absent subscriber gets its messages when the station with its answerback connects again
+	c.conn.Close()
+	for deadline := time.Now().Add(2 * time.Second); e.Subscribers()[2].Present; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expect 1003 absent")
+		}
+	}
+	a.send("/1003+")
+	a.expect("MSG")
+	a.send("CALL ME NNNN")
+	a.expect("STORED")
+
+	c = dialStation(t, addr, "CHARLIE", "1003")
+	c.expect("CALL ME")
+
+	// messages stored during a call wait for a subscriber dropping the call
+	b.send("1003+")
+	b.expect("CHARLIE")
+	c.expect("BRAVO")
+	a.send("/1002+")
+	a.expect("MSG")
+	a.send("HELLO NNNN")
+	a.expect("STORED")
+	b.conn.Close()
+	c.expectCodes([]byte{LineClear})
+	b = dialStation(t, addr, "BRAVO", "1002")
+	b.expect("HELLO")
+	// a new station gets a new number
+	dialStation(t, addr, "DELTA", "1004")
+
+	a.send("/1099+")
+	a.expect("1099 NP")
+}
+
+func TestExchangeStalledStation(t *testing.T) {
+	e, addr := serveTestExchange(t, ExchangeOptions{AnswerbackTimeout: 50 * time.Millisecond, MaxQueued: 4096})
+	a := dialStation(t, addr, "ALPHA", "1001")
+	b := dialStation(t, addr, "BRAVO", "1002")
+
+	a.send("1002+")
+	a.expect("BRAVO")
+	b.expect("ALPHA")
+
+	// 1002 stops reading, once the socket buffers and its queue are full it's disconnected
+	traffic := bytes.Repeat([]byte{10, 21}, 32<<10)
+	for sent := 0; e.Subscribers()[1].Present; sent += len(traffic) {
+		if sent > 64<<20 {
+			t.Fatalf("expect 1002 disconnected")
+		}
+		a.write(traffic)
+	}
+	a.expectCodes([]byte{LineClear})
+
+	if subscribers := e.Subscribers(); subscribers[0].Busy || subscribers[1].Present {
+		t.Errorf("expect 1001 free and 1002 absent, got %v", subscribers)
+	}
+}
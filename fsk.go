@@ -0,0 +1,234 @@
+/*
+ * Audio frequency shift keying of 5 bit codes, the way teleprinters are sent over phone lines and radio.
+ * Every code is sent asynchronously: a start bit(space tone), the 5 bits starting from the least significant one(1 is the mark tone)
+ * and the stop bits(mark tone). The modulator keeps the phase continuous between bits and calls.
+ * The demodulator compares the energy of both tones over half a bit, finds the start bit on the mark to space edge
+ * and samples the following bits in their middle.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type FSKOptions struct {
+	SampleRate int
+	Baud       float64
+	MarkHz     float64
+	SpaceHz    float64
+	StopBits   float64
+	// Amplitude of the tones, between 0 and 1, 0 uses 0.5
+	Amplitude float64
+	// Squelch is the tone amplitude under which the demodulator reports no carrier, 0 uses 0.05
+	Squelch float64
+}
+
+func (o FSKOptions) validate() error {
+	if o.SampleRate <= 0 || o.Baud <= 0 || o.StopBits < 1 {
+		return fmt.Errorf("Invalid line speed: %v baud, %v stop bits, %d Hz sample rate", o.Baud, o.StopBits, o.SampleRate)
+	}
+	if o.MarkHz <= 0 || o.SpaceHz <= 0 || o.MarkHz == o.SpaceHz || math.Max(o.MarkHz, o.SpaceHz)*2 >= float64(o.SampleRate) {
+		return fmt.Errorf("Invalid tones: %v Hz and %v Hz at %d Hz sample rate", o.MarkHz, o.SpaceHz, o.SampleRate)
+	}
+	if o.Amplitude < 0 || o.Amplitude > 1 {
+		return fmt.Errorf("Invalid amplitude: %v", o.Amplitude)
+	}
+
+	return nil
+}
+
+func (o FSKOptions) samplesPerBit() float64 {
+	return float64(o.SampleRate) / o.Baud
+}
+
+type fskModulator struct {
+	options FSKOptions
+	phase   float64
+	// time in samples, the fraction carries over so bits don't drift
+	clock float64
+	sent  int
+}
+
+// NewFSKModulator creates a modulator turning codes into audio samples between -1 and 1
+func NewFSKModulator(options FSKOptions) (*fskModulator, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if options.Amplitude == 0 {
+		options.Amplitude = 0.5
+	}
+
+	return &fskModulator{options: options}, nil
+}
+
+// Modulate returns the samples of codes
+func (m *fskModulator) Modulate(codes []byte) []float64 {
+	var samples []float64
+	for _, code := range codes {
+		samples = m.tone(samples, m.options.SpaceHz, 1)
+		for bit := 0; bit < 5; bit++ {
+			if code&(1<<bit) != 0 {
+				samples = m.tone(samples, m.options.MarkHz, 1)
+			} else {
+				samples = m.tone(samples, m.options.SpaceHz, 1)
+			}
+		}
+		samples = m.tone(samples, m.options.MarkHz, m.options.StopBits)
+	}
+
+	return samples
+}
+
+// Mark returns the samples of the mark tone held for d, e.g. to hold the carrier
+func (m *fskModulator) Mark(d time.Duration) []float64 {
+	return m.tone(nil, m.options.MarkHz, d.Seconds()*m.options.Baud)
+}
+
+// Silence returns d of silence, the carrier is off
+func (m *fskModulator) Silence(d time.Duration) []float64 {
+	m.clock += d.Seconds() * float64(m.options.SampleRate)
+	n := int(math.Round(m.clock)) - m.sent
+	m.sent += n
+
+	return make([]float64, n)
+}
+
+// tone appends a tone lasting bits bit times
+func (m *fskModulator) tone(samples []float64, hz float64, bits float64) []float64 {
+	m.clock += bits * m.options.samplesPerBit()
+	step := 2 * math.Pi * hz / float64(m.options.SampleRate)
+	for ; float64(m.sent) < math.Round(m.clock); m.sent++ {
+		samples = append(samples, m.options.Amplitude*math.Sin(m.phase))
+		m.phase = math.Mod(m.phase+step, 2*math.Pi)
+	}
+
+	return samples
+}
+
+type fskDemodulator struct {
+	options FSKOptions
+	window  []float64
+	// running correlations of the window with both tones
+	mark, space [2]float64
+	n           int
+	// the sample the next bit of the current code is read at, 0 when waiting for a start bit
+	next      float64
+	bit       int
+	code      byte
+	lastMark  bool
+	carrier   bool
+	quiet     int
+	lostCodes int
+}
+
+// NewFSKDemodulator creates a demodulator turning audio samples back into codes
+func NewFSKDemodulator(options FSKOptions) (*fskDemodulator, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if options.Squelch == 0 {
+		options.Squelch = 0.05
+	}
+
+	return &fskDemodulator{
+		options: options,
+		window:  make([]float64, int(options.samplesPerBit()/2)),
+	}, nil
+}
+
+// Carrier reports whether a tone is being received
+func (d *fskDemodulator) Carrier() bool {
+	return d.carrier
+}
+
+// FramingErrors returns the number of codes dropped because their stop bit was missing
+func (d *fskDemodulator) FramingErrors() int {
+	return d.lostCodes
+}
+
+// Feed demodulates samples, the state is kept between calls so samples can come in blocks of any size
+func (d *fskDemodulator) Feed(samples []float64) []byte {
+	var codes []byte
+	for _, sample := range samples {
+		isMark, level := d.detect(sample)
+		d.n++
+
+		// the carrier drops after a bit time without tone
+		if level >= d.options.Squelch {
+			d.carrier = true
+			d.quiet = 0
+		} else if d.quiet++; float64(d.quiet) > d.options.samplesPerBit() {
+			d.carrier = false
+			d.next = 0
+		}
+		if !d.carrier {
+			d.lastMark = false
+			continue
+		}
+
+		if d.next == 0 {
+			// the detector reports the edge as late as it reports the middle of a bit, so bits are read at (k+0.5) bit times from the reported edge
+			if d.lastMark && !isMark {
+				d.next = float64(d.n) + d.options.samplesPerBit()/2
+				d.bit = 0
+				d.code = 0
+			}
+			d.lastMark = isMark
+			continue
+		}
+		if float64(d.n) < d.next {
+			continue
+		}
+
+		switch {
+		case d.bit == 0 && isMark:
+			// too short to be a start bit
+			d.next = 0
+		case d.bit >= 1 && d.bit <= 5:
+			if isMark {
+				d.code |= 1 << (d.bit - 1)
+			}
+		case d.bit == 6:
+			if isMark {
+				codes = append(codes, d.code)
+			} else {
+				d.lostCodes++
+			}
+			d.next = 0
+		}
+		if d.next != 0 {
+			d.bit++
+			d.next += d.options.samplesPerBit()
+		}
+		d.lastMark = isMark
+	}
+
+	return codes
+}
+
+// detect returns whether the mark tone is stronger and the amplitude of the stronger tone over the window
+func (d *fskDemodulator) detect(sample float64) (bool, float64) {
+	size := len(d.window)
+	i := d.n % size
+	old := d.window[i]
+	d.window[i] = sample
+
+	t := float64(d.n)
+	oldT := float64(d.n - size)
+	for _, tone := range []struct {
+		hz   float64
+		corr *[2]float64
+	}{{d.options.MarkHz, &d.mark}, {d.options.SpaceHz, &d.space}} {
+		w := 2 * math.Pi * tone.hz / float64(d.options.SampleRate)
+		tone.corr[0] += sample*math.Cos(w*t) - old*math.Cos(w*oldT)
+		tone.corr[1] += sample*math.Sin(w*t) - old*math.Sin(w*oldT)
+	}
+
+	mark := math.Hypot(d.mark[0], d.mark[1])
+	space := math.Hypot(d.space[0], d.space[1])
+
+	return mark > space, 2 * math.Max(mark, space) / float64(size)
+}
@@ -0,0 +1,256 @@
+/*
+ * Gateway between ASCII(ITA5) text and a 5-unit teleprinter circuit.
+ * Unlike Encode/Decode, a gateway keeps the register and carriage state between calls, so a stream can be converted piece by piece.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type NewlineMode byte
+
+const (
+	// LF is sent as CR CR LF, giving the carriage time to return
+	NewlineCRCRLF NewlineMode = 0
+	// LF is sent as CR LF
+	NewlineCRLF NewlineMode = 1
+)
+
+const defaultTabWidth = 8
+
+type GatewayOptions struct {
+	Newline NewlineMode
+	// tab stops every TabWidth columns, 0 means 8
+	TabWidth int
+	// Substitutions replace characters missing from the variant, e.g. '@' => "(AT)"
+	Substitutions map[rune]string
+	// IgnoreError drops characters and codes which can't be converted instead of returning an error
+	IgnoreError bool
+}
+
+type gateway struct {
+	ver     version
+	options GatewayOptions
+
+	// ASCII to Baudot
+	started    bool
+	encCharset Charset
+	column     int
+	pendingCR  bool
+
+	// Baudot to ASCII
+	decCharset Charset
+}
+
+// NewGateway creates a gateway for the variant of codec, which must be one of the codecs of this package
+func NewGateway(codec Codec, options GatewayOptions) (*gateway, error) {
+	v, ok := codec.(versioned)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported codec: %T", codec)
+	}
+	if options.TabWidth <= 0 {
+		options.TabWidth = defaultTabWidth
+	}
+
+	return &gateway{
+		ver:     v.ver(),
+		options: options,
+	}, nil
+}
+
+// Reset forgets the register and carriage state of both directions
+func (g *gateway) Reset() {
+	g.started = false
+	g.encCharset = Letters
+	g.column = 0
+	g.pendingCR = false
+	g.decCharset = Letters
+}
+
+// Encode converts ASCII text into Baudot codes, the first call starts the stream with a LS control.
+// On error no codes are returned and the state is left as it was before the call.
+func (g *gateway) Encode(text string) ([]byte, error) {
+	saved := *g
+	codes, err := g.encode(text)
+	if err != nil {
+		*g = saved
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (g *gateway) encode(text string) ([]byte, error) {
+	ls, err := shiftCode(g.ver, Letters)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []byte
+	if !g.started {
+		g.started = true
+		codes = append(codes, ls)
+	}
+
+	for _, char := range text {
+		// CR LF in the input is a single newline
+		if g.pendingCR {
+			g.pendingCR = false
+			if char != '\n' {
+				codes, err = g.appendChar(codes, '\r')
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		switch char {
+		case '\r':
+			g.pendingCR = true
+		case '\n':
+			codes, err = g.appendNewline(codes)
+		case '\t':
+			for n := g.options.TabWidth - g.column%g.options.TabWidth; n > 0 && err == nil; n-- {
+				codes, err = g.appendChar(codes, ' ')
+			}
+		default:
+			codes, err = g.appendChar(codes, unicode.ToUpper(char))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+// Flush returns the codes held back while waiting for the character after a CR
+func (g *gateway) Flush() ([]byte, error) {
+	if !g.pendingCR {
+		return []byte{}, nil
+	}
+
+	codes, err := g.appendChar(nil, '\r')
+	if err != nil {
+		return nil, err
+	}
+	g.pendingCR = false
+
+	return codes, nil
+}
+
+// Decode converts Baudot codes into ASCII text, every LF becomes a Unix newline and CR is dropped.
+// On error the register is left as it was before the call.
+func (g *gateway) Decode(codes []byte) (string, error) {
+	charset := g.decCharset
+	text, err := g.decode(codes)
+	if err != nil {
+		g.decCharset = charset
+		return "", err
+	}
+
+	return text, nil
+}
+
+func (g *gateway) decode(codes []byte) (string, error) {
+	var sb strings.Builder
+	for _, code := range codes {
+		ch, shiftedCharset, err := decodeChar(code, g.decCharset, g.ver)
+		if err != nil {
+			if g.options.IgnoreError {
+				continue
+			}
+			return "", err
+		}
+
+		if shiftedCharset != g.decCharset {
+			g.decCharset = shiftedCharset
+			continue
+		}
+
+		switch ch {
+		case '\u0000', '\r':
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func (g *gateway) appendNewline(codes []byte) ([]byte, error) {
+	sequence := "\r\r\n"
+	if g.options.Newline == NewlineCRLF {
+		sequence = "\r\n"
+	}
+
+	var err error
+	for _, char := range sequence {
+		if codes, err = g.appendChar(codes, char); err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+// appendChar appends a character, substituting it if the variant doesn't have it, substitutes are not substituted again
+func (g *gateway) appendChar(codes []byte, char rune) ([]byte, error) {
+	result, err := g.appendCode(codes, char)
+	if err == nil {
+		return result, nil
+	}
+
+	substitute, ok := g.options.Substitutions[char]
+	if !ok {
+		if g.options.IgnoreError {
+			return codes, nil
+		}
+		return nil, err
+	}
+
+	for _, subChar := range substitute {
+		result, err := g.appendCode(codes, unicode.ToUpper(subChar))
+		if err != nil {
+			if g.options.IgnoreError {
+				continue
+			}
+			return nil, fmt.Errorf("Invalid substitute for %c: %v", char, err)
+		}
+		codes = result
+	}
+
+	return codes, nil
+}
+
+// appendCode appends the code of a character, preceded by a shift code if needed
+func (g *gateway) appendCode(codes []byte, char rune) ([]byte, error) {
+	code, shiftedCharset, err := encodeChar(char, g.encCharset, g.ver)
+	if err != nil {
+		return nil, err
+	}
+
+	if shiftedCharset != g.encCharset {
+		shifters, err := shiftPath(g.ver, g.encCharset, shiftedCharset)
+		if err != nil {
+			return nil, err
+		}
+		g.encCharset = shiftedCharset
+		codes = append(codes, shifters...)
+	}
+
+	switch char {
+	case '\r':
+		g.column = 0
+	case '\n', '\u0000':
+	default:
+		g.column++
+	}
+
+	return append(codes, code), nil
+}
@@ -0,0 +1,152 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGatewayEncode(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		codec      Codec
+		options    GatewayOptions
+		text       string
+		expect     string
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test lowercase and CR CR LF",
+			codec:      NewITA2(false),
+			text:       "ok\n",
+			expect:     "OK\r\r\n",
+			failedText: "expect 'OK\\r\\r\\n', got %v",
+		},
+		{
+			caseName:   "test CR LF input with CR LF newline",
+			codec:      NewITA2(false),
+			options:    GatewayOptions{Newline: NewlineCRLF},
+			text:       "a\r\nb\n",
+			expect:     "A\r\nB\r\n",
+			failedText: "expect 'A\\r\\nB\\r\\n', got %v",
+		},
+		{
+			caseName:   "test tab stops",
+			codec:      NewITA2(false),
+			options:    GatewayOptions{TabWidth: 4},
+			text:       "ab\tc\n\td",
+			expect:     "AB  C\r\r\n    D",
+			failedText: "expect 'AB  C\\r\\r\\n    D', got %v",
+		},
+		{
+			caseName:   "test substitutions",
+			codec:      NewITA2(false),
+			options:    GatewayOptions{Substitutions: map[rune]string{'@': "(at)", '$': "USD"}},
+			text:       "a@b $5",
+			expect:     "A(AT)B USD5",
+			failedText: "expect 'A(AT)B USD5', got %v",
+		},
+		{
+			caseName:   "test missing char",
+			codec:      NewITA2(false),
+			text:       "a@b",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test missing char, ignore error",
+			codec:      NewUSTTY(false),
+			options:    GatewayOptions{IgnoreError: true},
+			text:       "1+1",
+			expect:     "11",
+			failedText: "expect '11', got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			g, err := NewGateway(tc.codec, tc.options)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			codes, err := g.Encode(tc.text)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+				}
+				return
+			}
+
+			str, _ := tc.codec.Decode(codes)
+			if tc.shouldFail || str != tc.expect {
+				t.Errorf(tc.failedText, fmt.Sprintf("%q, %v", str, err))
+			}
+		})
+	}
+}
+
+func TestGatewayStream(t *testing.T) {
+	g, _ := NewGateway(NewITA2(false), GatewayOptions{Newline: NewlineCRLF})
+
+	var codes []byte
+	for _, chunk := range []string{"line 1\r", "\nline 2", "\r"} {
+		c, err := g.Encode(chunk)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		codes = append(codes, c...)
+	}
+	c, _ := g.Flush()
+	codes = append(codes, c...)
+
+	if str, _ := NewITA2(false).Decode(codes); str != "LINE 1\r\nLINE 2\r" {
+		t.Errorf("expect 'LINE 1\\r\\nLINE 2\\r', got %q", str)
+	}
+
+	// decode in pieces, the register is kept between calls
+	var text string
+	for _, piece := range [][]byte{codes[:5], codes[5:9], codes[9:]} {
+		str, err := g.Decode(piece)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		text += str
+	}
+	if text != "LINE 1\nLINE 2" {
+		t.Errorf("expect 'LINE 1\\nLINE 2', got %q", text)
+	}
+
+	b, _ := NewITA2(false).Encode("A\r\r\nB\r\n\r\nC")
+	if str, _ := g.Decode(b); str != "A\nB\n\nC" {
+		t.Errorf("expect 'A\\nB\\n\\nC', got %q", str)
+	}
+}
+
+func TestGatewayErrorKeepsState(t *testing.T) {
+	g, _ := NewGateway(NewITA2(false), GatewayOptions{})
+
+	if _, err := g.Encode("1$"); err == nil {
+		t.Fatalf("expect an error for '$'")
+	}
+	// the failed call sent nothing, so the stream still starts with LS and shifts to figures
+	codes, err := g.Encode("2")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if str, _ := NewITA2(false).Decode(codes); len(codes) != 3 || str != "2" {
+		t.Errorf("expect LS FS 2, got %v(%q)", codes, str)
+	}
+
+	if _, err := g.Decode([]byte{27, 40}); err == nil {
+		t.Fatalf("expect an error for code 40")
+	}
+	if str, _ := g.Decode([]byte{16}); str != "T" {
+		t.Errorf("expect the register kept in letters, got %q", str)
+	}
+}
+
+func TestGatewayUnsupportedCodec(t *testing.T) {
+	if _, err := NewGateway(NewSITORB(false), GatewayOptions{}); err == nil {
+		t.Errorf("expect an error for unsupported codec")
+	}
+}
@@ -1 +1,3 @@
 module github.com/hsldymq/baudot
+
+go 1.21
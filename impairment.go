@@ -0,0 +1,308 @@
+/*
+ * Channel impairment simulator to test the robustness of receivers. Both simulators are seeded so a test sees the same errors on every run.
+ * On codes: random bit errors, bursts of errors, dropped and inserted characters, and lost shift codes which put the receiver in the wrong register.
+ * On audio: additive white Gaussian noise, frequency offset(e.g. a mistuned radio) and selective fading,
+ * the fading is a second path whose strength changes slowly, it cancels some frequencies more than others as the paths interfere.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+)
+
+type ImpairmentOptions struct {
+	Seed int64
+	// Bits of a code, 0 uses 5, e.g. 7 for CCIR 476 signals
+	Bits int
+	// BitErrorRate is the probability of each bit to be flipped
+	BitErrorRate float64
+	// BurstRate is the probability of a burst to start at a code, the BurstLength codes of a burst are replaced by random codes
+	BurstRate   float64
+	BurstLength int
+	// DropRate is the probability of a code to be lost
+	DropRate float64
+	// InsertRate is the probability of a random code to be inserted before a code
+	InsertRate float64
+	// ShiftLossRate is the probability of a shift code to be lost
+	ShiftLossRate float64
+	// Codec tells the shift codes, nil uses ITA2
+	Codec Codec
+}
+
+// ImpairmentStats counts the impairments applied so far
+type ImpairmentStats struct {
+	Codes      int
+	BitErrors  int
+	Bursts     int
+	Dropped    int
+	Inserted   int
+	ShiftsLost int
+}
+
+type impairer struct {
+	rnd       *rand.Rand
+	options   ImpairmentOptions
+	shifts    map[byte]bool
+	burstLeft int
+	stats     ImpairmentStats
+}
+
+// NewImpairer creates a seeded code channel simulator
+func NewImpairer(options ImpairmentOptions) (*impairer, error) {
+	if options.Bits == 0 {
+		options.Bits = 5
+	}
+	if options.Bits < 1 || options.Bits > 8 {
+		return nil, fmt.Errorf("Invalid bits: %d", options.Bits)
+	}
+	for _, rate := range []float64{options.BitErrorRate, options.BurstRate, options.DropRate, options.InsertRate, options.ShiftLossRate} {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("Invalid rate: %v", rate)
+		}
+	}
+	if options.BurstRate > 0 && options.BurstLength <= 0 {
+		return nil, fmt.Errorf("Invalid burst length: %d", options.BurstLength)
+	}
+
+	ver := versionITA2
+	if options.Codec != nil {
+		v, ok := options.Codec.(versioned)
+		if !ok {
+			return nil, fmt.Errorf("Unsupported codec: %T", options.Codec)
+		}
+		ver = v.ver()
+	}
+	t, err := lookupTables(ver)
+	if err != nil {
+		return nil, err
+	}
+	shifts := map[byte]bool{}
+	for _, register := range t.shifts {
+		for code := range register {
+			shifts[code] = true
+		}
+	}
+
+	return &impairer{
+		rnd:     rand.New(rand.NewSource(options.Seed)),
+		options: options,
+		shifts:  shifts,
+	}, nil
+}
+
+// Stats returns the impairments applied so far
+func (im *impairer) Stats() ImpairmentStats {
+	return im.stats
+}
+
+// Impair passes codes through the channel
+func (im *impairer) Impair(codes []byte) []byte {
+	mask := byte(1<<im.options.Bits - 1)
+	out := make([]byte, 0, len(codes))
+	for _, code := range codes {
+		im.stats.Codes++
+
+		if im.rnd.Float64() < im.options.InsertRate {
+			im.stats.Inserted++
+			out = append(out, byte(im.rnd.Intn(int(mask)+1)))
+		}
+		if im.rnd.Float64() < im.options.DropRate {
+			im.stats.Dropped++
+			continue
+		}
+		if im.shifts[code] && im.rnd.Float64() < im.options.ShiftLossRate {
+			im.stats.ShiftsLost++
+			continue
+		}
+
+		if im.burstLeft == 0 && im.rnd.Float64() < im.options.BurstRate {
+			im.stats.Bursts++
+			im.burstLeft = im.options.BurstLength
+		}
+		if im.burstLeft > 0 {
+			im.burstLeft--
+			out = append(out, byte(im.rnd.Intn(int(mask)+1)))
+			continue
+		}
+
+		for bit := 0; bit < im.options.Bits; bit++ {
+			if im.rnd.Float64() < im.options.BitErrorRate {
+				im.stats.BitErrors++
+				code ^= 1 << bit
+			}
+		}
+		out = append(out, code&mask)
+	}
+
+	return out
+}
+
+type impairedReader struct {
+	r       io.Reader
+	im      *impairer
+	pending []byte
+	// err of r, returned once the pending codes are read
+	err error
+}
+
+// NewImpairedReader returns a reader of the codes read from r passed through the channel
+func NewImpairedReader(r io.Reader, im *impairer) *impairedReader {
+	return &impairedReader{r: r, im: im}
+}
+
+// Read returns the impaired codes, codes dropped by the channel may leave a read of r with nothing to return, r is then read again
+func (ir *impairedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for len(ir.pending) == 0 {
+		if ir.err != nil {
+			return 0, ir.err
+		}
+		buf := make([]byte, len(p))
+		n, err := ir.r.Read(buf)
+		ir.pending = ir.im.Impair(buf[:n])
+		ir.err = err
+	}
+
+	n := copy(p, ir.pending)
+	ir.pending = ir.pending[n:]
+
+	return n, nil
+}
+
+type impairedWriter struct {
+	w  io.Writer
+	im *impairer
+}
+
+// NewImpairedWriter returns a writer passing codes through the channel before writing them to w
+func NewImpairedWriter(w io.Writer, im *impairer) *impairedWriter {
+	return &impairedWriter{w: w, im: im}
+}
+
+// Write reports all of p as written when the impaired codes are written, even if codes were dropped or inserted
+func (iw *impairedWriter) Write(p []byte) (int, error) {
+	if _, err := iw.w.Write(iw.im.Impair(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+type AudioImpairmentOptions struct {
+	Seed       int64
+	SampleRate int
+	// Noise is the standard deviation of the added white Gaussian noise, see NoiseForSNR
+	Noise float64
+	// FrequencyOffset moves every frequency of the signal by Hz
+	FrequencyOffset float64
+	// FadingDepth is the strength of the second path at its strongest, from 0(no fading) to 1(full cancellation)
+	FadingDepth float64
+	// FadingDelay of the second path, the frequencies faded most are 1/FadingDelay Hz apart
+	FadingDelay time.Duration
+	// FadingRate is how many times per second the second path goes from weakest to strongest and back
+	FadingRate float64
+}
+
+// NoiseForSNR returns the noise level giving snrDB of signal to noise ratio for a tone of amplitude
+func NoiseForSNR(amplitude float64, snrDB float64) float64 {
+	return amplitude / math.Sqrt2 / math.Pow(10, snrDB/20)
+}
+
+// taps of the Hilbert transformer used to shift frequencies
+const hilbertTaps = 63
+
+type audioImpairer struct {
+	rnd     *rand.Rand
+	options AudioImpairmentOptions
+	n       int
+	// ring buffer of the past input samples for the Hilbert transformer and the second path, head is the latest one
+	history []float64
+	head    int
+	hilbert []float64
+	delay   int
+}
+
+// NewAudioImpairer creates a seeded audio channel simulator
+func NewAudioImpairer(options AudioImpairmentOptions) (*audioImpairer, error) {
+	if options.SampleRate <= 0 {
+		return nil, fmt.Errorf("Invalid sample rate: %d", options.SampleRate)
+	}
+	if options.Noise < 0 || options.FadingDepth < 0 || options.FadingDepth > 1 || options.FadingRate < 0 || options.FadingDelay < 0 {
+		return nil, fmt.Errorf("Invalid impairment: noise %v, fading depth %v, rate %v, delay %v", options.Noise, options.FadingDepth, options.FadingRate, options.FadingDelay)
+	}
+
+	delay := int(math.Round(options.FadingDelay.Seconds() * float64(options.SampleRate)))
+	hilbert := make([]float64, hilbertTaps)
+	for i := range hilbert {
+		k := i - hilbertTaps/2
+		if k%2 != 0 {
+			// windowed ideal Hilbert transformer
+			window := 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(hilbertTaps-1))
+			hilbert[i] = 2 / (math.Pi * float64(k)) * window
+		}
+	}
+
+	return &audioImpairer{
+		rnd:     rand.New(rand.NewSource(options.Seed)),
+		options: options,
+		history: make([]float64, hilbertTaps+delay),
+		hilbert: hilbert,
+		delay:   delay,
+	}, nil
+}
+
+// Impair passes samples through the channel, the state is kept between calls.
+// A frequency offset delays the signal by half the length of the Hilbert transformer
+func (a *audioImpairer) Impair(samples []float64) []float64 {
+	out := make([]float64, len(samples))
+	rate := float64(a.options.SampleRate)
+	for i, sample := range samples {
+		a.head = (a.head + 1) % len(a.history)
+		a.history[a.head] = sample
+		t := float64(a.n) / rate
+		a.n++
+
+		// selective fading: the second path interferes with the direct one
+		faded := func(back int) float64 {
+			s := a.past(back)
+			if a.options.FadingDepth == 0 {
+				return s
+			}
+			gain := a.options.FadingDepth * (1 - math.Cos(2*math.Pi*a.options.FadingRate*t)) / 2
+			if a.options.FadingRate == 0 {
+				gain = a.options.FadingDepth
+			}
+			return s - gain*a.past(back+a.delay)
+		}
+
+		y := faded(0)
+		if a.options.FrequencyOffset != 0 {
+			// single sideband shift of the analytic signal
+			var h float64
+			for k, tap := range a.hilbert {
+				if tap != 0 {
+					h += tap * faded(k)
+				}
+			}
+			w := 2 * math.Pi * a.options.FrequencyOffset * t
+			y = faded(hilbertTaps/2)*math.Cos(w) - h*math.Sin(w)
+		}
+
+		out[i] = y + a.rnd.NormFloat64()*a.options.Noise
+	}
+
+	return out
+}
+
+// past returns the input sample back samples before the latest one
+func (a *audioImpairer) past(back int) float64 {
+	return a.history[(a.head-back+len(a.history))%len(a.history)]
+}
@@ -0,0 +1,224 @@
+package baudot
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestImpairer(t *testing.T) {
+	codes, _ := NewITA2(false).Encode("THE QUICK BROWN FOX 1234 JUMPS OVER THE LAZY DOG 5678")
+	tt := []struct {
+		caseName string
+		options  ImpairmentOptions
+		check    func(out []byte, stats ImpairmentStats) bool
+	}{
+		{
+			caseName: "test clear channel",
+			options:  ImpairmentOptions{},
+			check: func(out []byte, stats ImpairmentStats) bool {
+				return string(out) == string(codes) && stats.Codes == len(codes)
+			},
+		},
+		{
+			caseName: "test dropped codes",
+			options:  ImpairmentOptions{DropRate: 1},
+			check: func(out []byte, stats ImpairmentStats) bool {
+				return len(out) == 0 && stats.Dropped == len(codes)
+			},
+		},
+		{
+			caseName: "test inserted codes",
+			options:  ImpairmentOptions{InsertRate: 1},
+			check: func(out []byte, stats ImpairmentStats) bool {
+				return len(out) == 2*len(codes) && stats.Inserted == len(codes)
+			},
+		},
+		{
+			caseName: "test lost shift codes",
+			options:  ImpairmentOptions{ShiftLossRate: 1},
+			check: func(out []byte, stats ImpairmentStats) bool {
+				str, _ := NewITA2(true).Decode(out)
+				return stats.ShiftsLost == 4 && len(out) == len(codes)-4 && str == "THE QUICK BROWN FOX QWER JUMPS OVER THE LAZY DOG TYUI"
+			},
+		},
+		{
+			caseName: "test bit errors",
+			options:  ImpairmentOptions{Seed: 1, BitErrorRate: 0.05},
+			check: func(out []byte, stats ImpairmentStats) bool {
+				flipped := 0
+				for i := range out {
+					for diff := out[i] ^ codes[i]; diff != 0; diff &= diff - 1 {
+						flipped++
+					}
+				}
+				return len(out) == len(codes) && flipped == stats.BitErrors && flipped > 0
+			},
+		},
+		{
+			caseName: "test bursts",
+			options:  ImpairmentOptions{Seed: 1, BurstRate: 0.05, BurstLength: 4},
+			check: func(out []byte, stats ImpairmentStats) bool {
+				return len(out) == len(codes) && stats.Bursts > 0 && string(out) != string(codes)
+			},
+		},
+		{
+			caseName: "test 7 bit signals",
+			options:  ImpairmentOptions{Seed: 1, Bits: 7, BitErrorRate: 0.5},
+			check: func(out []byte, stats ImpairmentStats) bool {
+				for _, signal := range out {
+					if signal >= 1<<5 {
+						return true
+					}
+				}
+				return false
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			im, err := NewImpairer(tc.options)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			out := im.Impair(codes)
+			if !tc.check(out, im.Stats()) {
+				t.Errorf("unexpected output %v, stats %+v", out, im.Stats())
+			}
+		})
+	}
+}
+
+func TestImpairerSeed(t *testing.T) {
+	codes := bytes.Repeat([]byte{1, 2, 3, 4, 5}, 20)
+	options := ImpairmentOptions{Seed: 42, BitErrorRate: 0.02, DropRate: 0.02, InsertRate: 0.02}
+	a, _ := NewImpairer(options)
+	b, _ := NewImpairer(options)
+	if string(a.Impair(codes)) != string(b.Impair(codes)) {
+		t.Errorf("expect the same output for the same seed")
+	}
+
+	if _, err := NewImpairer(ImpairmentOptions{DropRate: 2}); err == nil {
+		t.Errorf("expect error for an invalid rate")
+	}
+	if _, err := NewImpairer(ImpairmentOptions{BurstRate: 0.1}); err == nil {
+		t.Errorf("expect error for a burst without length")
+	}
+}
+
+func TestImpairedReaderWriter(t *testing.T) {
+	codes, _ := NewITA2(false).Encode("RYRYRY")
+
+	im, _ := NewImpairer(ImpairmentOptions{ShiftLossRate: 1})
+	out, err := io.ReadAll(NewImpairedReader(bytes.NewReader(codes), im))
+	if err != nil || string(out) != string(codes[:1])+string(codes[2:]) {
+		t.Errorf("expect %v without LS, got %v, %v", codes, out, err)
+	}
+
+	// an error returned with the last codes is returned once they are read
+	im, _ = NewImpairer(ImpairmentOptions{})
+	out, err = io.ReadAll(NewImpairedReader(&failingReader{data: codes, err: io.ErrUnexpectedEOF}, im))
+	if err != io.ErrUnexpectedEOF || string(out) != string(codes) {
+		t.Errorf("expect %v and %v, got %v, %v", codes, io.ErrUnexpectedEOF, out, err)
+	}
+
+	var buf bytes.Buffer
+	im, _ = NewImpairer(ImpairmentOptions{DropRate: 1})
+	n, err := NewImpairedWriter(&buf, im).Write(codes)
+	if err != nil || n != len(codes) || buf.Len() != 0 {
+		t.Errorf("expect %d codes written and all dropped, got %d, %d, %v", len(codes), n, buf.Len(), err)
+	}
+}
+
+// failingReader returns its data with err, then io.EOF
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == nil {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data)
+	r.data = nil
+
+	return n, r.err
+}
+
+// toneLevel returns the amplitude of the hz component of samples
+func toneLevel(samples []float64, hz float64, sampleRate int) float64 {
+	var re, im float64
+	for i, s := range samples {
+		w := 2 * math.Pi * hz * float64(i) / float64(sampleRate)
+		re += s * math.Cos(w)
+		im += s * math.Sin(w)
+	}
+
+	return 2 * math.Hypot(re, im) / float64(len(samples))
+}
+
+func tone(hz float64, n int, sampleRate int) []float64 {
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = 0.5 * math.Sin(2*math.Pi*hz*float64(i)/float64(sampleRate))
+	}
+
+	return samples
+}
+
+func TestAudioImpairer(t *testing.T) {
+	const rate = 8000
+
+	a, _ := NewAudioImpairer(AudioImpairmentOptions{SampleRate: rate, FrequencyOffset: 100})
+	out := a.Impair(tone(1000, rate, rate))[hilbertTaps:]
+	if level := toneLevel(out, 1100, rate); math.Abs(level-0.5) > 0.02 {
+		t.Errorf("expect 0.5 at 1100 Hz, got %v", level)
+	}
+	if level := toneLevel(out, 1000, rate); level > 0.02 {
+		t.Errorf("expect nothing left at 1000 Hz, got %v", level)
+	}
+
+	// notches every 1600 Hz
+	a, _ = NewAudioImpairer(AudioImpairmentOptions{SampleRate: rate, FadingDepth: 1, FadingDelay: 625 * time.Microsecond})
+	if level := toneLevel(a.Impair(tone(1600, rate, rate)), 1600, rate); level > 0.01 {
+		t.Errorf("expect 1600 Hz faded, got %v", level)
+	}
+	a, _ = NewAudioImpairer(AudioImpairmentOptions{SampleRate: rate, FadingDepth: 1, FadingDelay: 625 * time.Microsecond})
+	if level := toneLevel(a.Impair(tone(800, rate, rate)), 800, rate); math.Abs(level-1) > 0.01 {
+		t.Errorf("expect 800 Hz doubled, got %v", level)
+	}
+
+	a, _ = NewAudioImpairer(AudioImpairmentOptions{SampleRate: rate, Seed: 1, Noise: NoiseForSNR(0.5, 0)})
+	out = a.Impair(make([]float64, rate))
+	var power float64
+	for _, s := range out {
+		power += s * s
+	}
+	if rms := math.Sqrt(power / rate); math.Abs(rms-0.5/math.Sqrt2) > 0.02 {
+		t.Errorf("expect noise as strong as the tone, got rms %v", rms)
+	}
+}
+
+func TestTDDThroughImpairedAudio(t *testing.T) {
+	sender, _ := NewTDDSession(TDDSessionOptions{})
+	samples, _ := sender.Send("CAN YOU READ ME 123 GA")
+
+	a, _ := NewAudioImpairer(AudioImpairmentOptions{
+		Seed:            7,
+		SampleRate:      TDDSampleRate,
+		Noise:           NoiseForSNR(0.5, 10),
+		FrequencyOffset: 20,
+		FadingDepth:     0.5,
+		FadingDelay:     time.Millisecond,
+		FadingRate:      0.5,
+	})
+	receiver, _ := NewTDDSession(TDDSessionOptions{})
+	text, err := receiver.Receive(a.Impair(append(samples, make([]float64, TDDSampleRate)...)))
+	if err != nil || text != "CAN YOU READ ME 123 GA" {
+		t.Errorf("expect 'CAN YOU READ ME 123 GA', got %q, %v", text, err)
+	}
+}
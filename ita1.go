@@ -1,30 +1,55 @@
 /*
  * ITA1(International Telegraph Alphabet No.1) is Original Baudot Code.
- * This is The UK Version.
+ * NewITA1 creates The UK Version, NewITA1Continental creates the continental(French) version
+ * which has accented letters and different figures assignments.
  */
 
 package baudot
 
 // Encode string into byte array represent the sequence of Baudot code
 func (c *ita1) Encode(msg string) ([]byte, error) {
-	return encode(msg, c.ignErr, versionITA1)
+	return encode(msg, c.ignErr, c.version)
 }
 
 // Decode Baudot code to string
 func (c *ita1) Decode(codes []byte) (string, error) {
-	return decode(codes, c.ignErr, versionITA1)
+	return decode(codes, c.ignErr, c.version)
 }
 
 // EncodeChar encodes a character into Baudot code
 func (c *ita1) EncodeChar(char rune, currentCharset Charset) (byte, bool, error) {
-	code, shiftedCharset, err := encodeChar(char, currentCharset, versionITA1)
+	code, shiftedCharset, err := encodeChar(char, currentCharset, c.version)
 
 	return code, shiftedCharset != currentCharset, err
 }
 
 // DecodeChar decodes a Baudot code to rune
 func (c *ita1) DecodeChar(code byte, currentCharset Charset) (rune, bool, error) {
-	char, shiftedCharset, err := decodeChar(code, currentCharset, versionITA1)
+	char, shiftedCharset, err := decodeChar(code, currentCharset, c.version)
 
 	return char, currentCharset != shiftedCharset, err
 }
+
+func (c *ita1) ver() version {
+	return c.version
+}
+
+// Repertoire returns the characters of a register in code order
+func (c *ita1) Repertoire(charset Charset) []rune {
+	return repertoire(c.version, charset)
+}
+
+// CanEncode reports whether every character of msg is in Baudot code
+func (c *ita1) CanEncode(msg string) bool {
+	return len(c.Unsupported(msg)) == 0
+}
+
+// Unsupported returns the characters of msg which are not in Baudot code
+func (c *ita1) Unsupported(msg string) []UnsupportedChar {
+	return unsupported(msg, c.version, identity)
+}
+
+// EncodedLen returns the number of codes Encode returns for msg, shift codes included
+func (c *ita1) EncodedLen(msg string) (int, error) {
+	return encodedLen(msg, c.ignErr, c.version)
+}
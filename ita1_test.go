@@ -0,0 +1,114 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestITA1Encode(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		codec      *ita1
+		msg        string
+		expect     []byte
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test UK version letters",
+			codec:      NewITA1(false),
+			msg:        "BAUDOT",
+			expect:     []byte{1, 18, 4, 20, 30, 28, 21},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{1, 18, 4, 20, 30, 28, 21}),
+		},
+		{
+			caseName:   "test continental version accented letter",
+			codec:      NewITA1Continental(false),
+			msg:        "ÉTÉ",
+			expect:     []byte{1, 12, 21, 12},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{1, 12, 21, 12}),
+		},
+		{
+			caseName:   "test continental version figures",
+			codec:      NewITA1Continental(false),
+			msg:        "N№5",
+			expect:     []byte{1, 27, 2, 27, 28},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{1, 27, 2, 27, 28}),
+		},
+		{
+			caseName:   "test accented letter in UK version",
+			codec:      NewITA1(false),
+			msg:        "ÉTÉ",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			codes, err := tc.codec.Encode(tc.msg)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+				}
+				return
+			}
+			if tc.shouldFail || string(codes) != string(tc.expect) {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+			}
+
+			str, err := tc.codec.Decode(codes)
+			if err != nil || str != tc.msg {
+				t.Errorf("expect %q to round trip, got %q, %v", tc.msg, str, err)
+			}
+		})
+	}
+}
+
+func TestITA1DecodeChar(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		codec      *ita1
+		code       byte
+		charset    Charset
+		expectChar rune
+	}{
+		{
+			caseName:   "test UK version letters code 12",
+			codec:      NewITA1(false),
+			code:       12,
+			charset:    Letters,
+			expectChar: '/',
+		},
+		{
+			caseName:   "test continental version letters code 12",
+			codec:      NewITA1Continental(false),
+			code:       12,
+			charset:    Letters,
+			expectChar: 'É',
+		},
+		{
+			caseName:   "test UK version figures code 27",
+			codec:      NewITA1(false),
+			code:       27,
+			charset:    Figures,
+			expectChar: '£',
+		},
+		{
+			caseName:   "test continental version figures code 31",
+			codec:      NewITA1Continental(false),
+			code:       31,
+			charset:    Figures,
+			expectChar: '%',
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			char, shifted, err := tc.codec.DecodeChar(tc.code, tc.charset)
+			if err != nil || shifted || char != tc.expectChar {
+				t.Errorf("expect %q, got %q, %v, %v", tc.expectChar, char, shifted, err)
+			}
+		})
+	}
+}
@@ -6,24 +6,48 @@ package baudot
 
 // Encode string into byte array represent the sequence of Baudot-Murray code(ITA2)
 func (c *ita2) Encode(msg string) ([]byte, error) {
-	return encode(msg, c.ignErr, versionITA2)
+	return encode(msg, c.ignErr, c.version)
 }
 
 // Decode Baudot-Murray code(ITA2) to string
 func (c *ita2) Decode(codes []byte) (string, error) {
-	return decode(codes, c.ignErr, versionITA2)
+	return decode(codes, c.ignErr, c.version)
 }
 
 // EncodeChar encodes a character into Baudot-Murray code(ITA2)
 func (c *ita2) EncodeChar(char rune, currentCharset Charset) (byte, bool, error) {
-	code, shiftedCharset, err := encodeChar(char, currentCharset, versionITA2)
+	code, shiftedCharset, err := encodeChar(char, currentCharset, c.version)
 
 	return code, shiftedCharset != currentCharset, err
 }
 
 // DecodeChar decodes a Baudot-Murray code(ITA2) to rune
 func (c *ita2) DecodeChar(code byte, currentCharset Charset) (rune, bool, error) {
-	char, shiftedCharset, err := decodeChar(code, currentCharset, versionITA2)
+	char, shiftedCharset, err := decodeChar(code, currentCharset, c.version)
 
 	return char, currentCharset != shiftedCharset, err
 }
+
+func (c *ita2) ver() version {
+	return c.version
+}
+
+// Repertoire returns the characters of a register in code order
+func (c *ita2) Repertoire(charset Charset) []rune {
+	return repertoire(c.version, charset)
+}
+
+// CanEncode reports whether every character of msg is in Baudot-Murray code(ITA2)
+func (c *ita2) CanEncode(msg string) bool {
+	return len(c.Unsupported(msg)) == 0
+}
+
+// Unsupported returns the characters of msg which are not in Baudot-Murray code(ITA2)
+func (c *ita2) Unsupported(msg string) []UnsupportedChar {
+	return unsupported(msg, c.version, identity)
+}
+
+// EncodedLen returns the number of codes Encode returns for msg, shift codes included
+func (c *ita2) EncodedLen(msg string) (int, error) {
+	return encodedLen(msg, c.ignErr, c.version)
+}
@@ -1,6 +1,7 @@
 package baudot
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -272,3 +273,75 @@ func TestITA2Decode(t *testing.T) {
 		})
 	}
 }
+
+func TestCodecError(t *testing.T) {
+	tt := []struct {
+		caseName string
+		convert  func() error
+		expect   CodecError
+	}{
+		{
+			caseName: "test encode",
+			convert:  func() error { _, err := NewITA2(false).Encode("£$"); return err },
+			expect:   CodecError{Kind: InvalidChar, Char: '$', Position: 1},
+		},
+		{
+			caseName: "test decode",
+			convert:  func() error { _, err := NewITA2(false).Decode([]byte{20, 27, 40}); return err },
+			expect:   CodecError{Kind: InvalidCode, Code: 40, Position: 2},
+		},
+		{
+			caseName: "test encode char",
+			convert:  func() error { _, _, err := NewITA2(false).EncodeChar('$', Letters); return err },
+			expect:   CodecError{Kind: InvalidChar, Char: '$', Position: -1},
+		},
+		{
+			caseName: "test invalid charset",
+			convert:  func() error { _, _, err := NewITA2(false).DecodeChar(1, Katakana); return err },
+			expect:   CodecError{Kind: InvalidCharset, Charset: Katakana, Position: -1},
+		},
+		{
+			// the position is in the message, not in the kana and marks voiced kana are sent as
+			caseName: "test katakana",
+			convert:  func() error { _, err := NewKatakana(false).Encode("ガギ@"); return err },
+			expect:   CodecError{Kind: InvalidChar, Char: '@', Position: 2},
+		},
+		{
+			caseName: "test optimized",
+			convert: func() error {
+				_, err := EncodeOptimized(NewKatakana(false), "ガギ@", OptimizeOptions{})
+				return err
+			},
+			expect: CodecError{Kind: InvalidChar, Char: '@', Position: 2},
+		},
+		{
+			caseName: "test tape",
+			convert:  func() error { _, err := TapeText([]byte{1, 32}); return err },
+			expect:   CodecError{Kind: InvalidCode, Code: 32, Position: 1},
+		},
+		{
+			caseName: "test ITA3 mutilated code",
+			convert:  func() error { _, err := NewITA3(false).Decode([]byte{0x15, 0x4B}); return err },
+			expect:   CodecError{Kind: MutilatedCode, Code: 0x4B, Position: 1},
+		},
+		{
+			caseName: "test ITA3 service signal",
+			convert:  func() error { _, err := NewITA3(false).Decode([]byte{0x15, 0x25, RQ_ITA3}); return err },
+			expect:   CodecError{Kind: InvalidCode, Code: RQ_ITA3, Position: 2},
+		},
+		{
+			caseName: "test ITA3 transcode",
+			convert:  func() error { _, err := ITA2ToITA3([]byte{1, 32}); return err },
+			expect:   CodecError{Kind: InvalidCode, Code: 32, Position: 1},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			var e *CodecError
+			if err := tc.convert(); !errors.As(err, &e) || *e != tc.expect {
+				t.Errorf("expect %+v, got %#v", tc.expect, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * ITA3(International Telegraph Alphabet No.3) is the 7-unit constant ratio code derived from ITA2 by Van Duuren for ARQ radio circuits.
+ * Every valid combination has exactly 3 marks and 4 spaces, so any single bit error is detected.
+ * ITA3 carries the same letters and figures registers as ITA2.
+ */
+
+package baudot
+
+import (
+	"math/bits"
+)
+
+const (
+	// ITA3 signal alpha, continuous idle signal
+	ALPHA_ITA3 byte = 0x70
+	// ITA3 signal beta, idle signal
+	BETA_ITA3 byte = 0x4C
+	// ITA3 signal repetition
+	RQ_ITA3 byte = 0x19
+)
+
+// ita2ToITA3 is indexed by ITA2 code
+var ita2ToITA3 = [32]byte{
+	0x15, 0x29, 0x13, 0x38, 0x23, 0x34, 0x32, 0x31,
+	0x07, 0x2C, 0x2A, 0x68, 0x26, 0x64, 0x62, 0x61,
+	0x0B, 0x1C, 0x1A, 0x58, 0x16, 0x54, 0x52, 0x51,
+	0x0E, 0x0D, 0x4A, 0x49, 0x46, 0x45, 0x43, 0x25,
+}
+
+var ita3ToITA2 = func() map[byte]byte {
+	m := make(map[byte]byte, len(ita2ToITA3))
+	for code, signal := range ita2ToITA3 {
+		m[signal] = byte(code)
+	}
+
+	return m
+}()
+
+type ita3 struct {
+	ignErr bool
+}
+
+func NewITA3(ignoreError bool) *ita3 {
+	return &ita3{
+		ignErr: ignoreError,
+	}
+}
+
+// Encode string into byte array represent the sequence of ITA3 code
+func (c *ita3) Encode(msg string) ([]byte, error) {
+	codes, err := encode(msg, c.ignErr, versionITA2)
+	if err != nil {
+		return nil, err
+	}
+
+	return ITA2ToITA3(codes)
+}
+
+// Decode ITA3 code to string
+func (c *ita3) Decode(codes []byte) (string, error) {
+	ita2Codes := make([]byte, 0, len(codes))
+	for i, code := range codes {
+		ita2Code, err := c.toITA2(code)
+		if err != nil {
+			if c.ignErr {
+				continue
+			}
+			return "", at(err, i)
+		}
+		ita2Codes = append(ita2Codes, ita2Code)
+	}
+
+	return decode(ita2Codes, c.ignErr, versionITA2)
+}
+
+// EncodeChar encodes a character into ITA3 code
+func (c *ita3) EncodeChar(char rune, currentCharset Charset) (byte, bool, error) {
+	code, shiftedCharset, err := encodeChar(char, currentCharset, versionITA2)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return ita2ToITA3[code], shiftedCharset != currentCharset, nil
+}
+
+// DecodeChar decodes an ITA3 code to rune
+func (c *ita3) DecodeChar(code byte, currentCharset Charset) (rune, bool, error) {
+	ita2Code, err := c.toITA2(code)
+	if err != nil {
+		return '\u0000', false, err
+	}
+
+	char, shiftedCharset, err := decodeChar(ita2Code, currentCharset, versionITA2)
+
+	return char, currentCharset != shiftedCharset, err
+}
+
+// Repertoire returns the characters of a register in code order, the repertoire of ITA2
+func (c *ita3) Repertoire(charset Charset) []rune {
+	return repertoire(versionITA2, charset)
+}
+
+// CanEncode reports whether every character of msg is in ITA3
+func (c *ita3) CanEncode(msg string) bool {
+	return len(c.Unsupported(msg)) == 0
+}
+
+// Unsupported returns the characters of msg which are not in ITA3
+func (c *ita3) Unsupported(msg string) []UnsupportedChar {
+	return unsupported(msg, versionITA2, identity)
+}
+
+// EncodedLen returns the number of codes Encode returns for msg, shift codes included, each ITA2 code is one ITA3 code
+func (c *ita3) EncodedLen(msg string) (int, error) {
+	return encodedLen(msg, c.ignErr, versionITA2)
+}
+
+func (c *ita3) toITA2(code byte) (byte, error) {
+	if !IsValidITA3(code) {
+		// always return error, not affect by ignErr field
+		return 0, &CodecError{Kind: MutilatedCode, Code: code, Position: -1}
+	}
+
+	ita2Code, ok := ita3ToITA2[code]
+	if !ok {
+		return 0, &CodecError{Kind: InvalidCode, Code: code, Position: -1}
+	}
+
+	return ita2Code, nil
+}
+
+// IsValidITA3 reports whether the code has the 3 marks of an ITA3 combination
+func IsValidITA3(code byte) bool {
+	return code < 0x80 && bits.OnesCount8(code) == 3
+}
+
+// ITA2ToITA3 transcodes a sequence of ITA2 codes into ITA3 codes
+func ITA2ToITA3(codes []byte) ([]byte, error) {
+	ita3Codes := make([]byte, 0, len(codes))
+	for i, code := range codes {
+		if int(code) >= len(ita2ToITA3) {
+			return nil, &CodecError{Kind: InvalidCode, Code: code, Position: i}
+		}
+		ita3Codes = append(ita3Codes, ita2ToITA3[code])
+	}
+
+	return ita3Codes, nil
+}
+
+// ITA3ToITA2 transcodes a sequence of ITA3 codes into ITA2 codes, mutilated codes and service signals are reported as errors
+func ITA3ToITA2(codes []byte) ([]byte, error) {
+	ita2Codes := make([]byte, 0, len(codes))
+	for i, code := range codes {
+		ita2Code, ok := ita3ToITA2[code]
+		if !ok {
+			return nil, &CodecError{Kind: InvalidCode, Code: code, Position: i}
+		}
+		ita2Codes = append(ita2Codes, ita2Code)
+	}
+
+	return ita2Codes, nil
+}
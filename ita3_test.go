@@ -0,0 +1,165 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestITA3Table(t *testing.T) {
+	seen := map[byte]bool{}
+	for code, signal := range ita2ToITA3 {
+		if !IsValidITA3(signal) {
+			t.Errorf("ITA3 code for ITA2 code %d should have 3 marks, got %#x", code, signal)
+		}
+		if seen[signal] {
+			t.Errorf("ITA3 code %#x is used more than once", signal)
+		}
+		seen[signal] = true
+	}
+
+	for _, signal := range []byte{ALPHA_ITA3, BETA_ITA3, RQ_ITA3} {
+		if !IsValidITA3(signal) || seen[signal] {
+			t.Errorf("service signal %#x should be valid and distinct from traffic codes", signal)
+		}
+	}
+}
+
+func TestITA3SingleBitError(t *testing.T) {
+	c := NewITA3(false)
+	for _, code := range ita2ToITA3 {
+		for bit := 0; bit < 7; bit++ {
+			if _, _, err := c.DecodeChar(code^(1<<bit), Letters); err == nil {
+				t.Errorf("flipping bit %d of %#x should be detected", bit, code)
+			}
+		}
+	}
+}
+
+func TestITA3EncodeChar(t *testing.T) {
+	tt := []struct {
+		caseName    string
+		char        rune
+		charset     Charset
+		expectCode  byte
+		expectShift bool
+		shouldFail  bool
+		failedText  string
+	}{
+		{
+			caseName:    "test regular char",
+			char:        'A',
+			charset:     Letters,
+			expectCode:  0x38,
+			expectShift: false,
+			shouldFail:  false,
+			failedText:  "code for 'A' should be 0x38, got %v",
+		},
+		{
+			caseName:    "test invalid char",
+			char:        '$',
+			charset:     Letters,
+			expectCode:  0,
+			expectShift: false,
+			shouldFail:  true,
+			failedText:  "encode code for char '$' should return error, got %v",
+		},
+		{
+			caseName:    "test shift to figures charset",
+			char:        '6',
+			charset:     Letters,
+			expectCode:  0x54,
+			expectShift: true,
+			shouldFail:  false,
+			failedText:  "value of shifted Should Be true, got %v",
+		},
+	}
+
+	c := NewITA3(false)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			code, shifted, err := c.EncodeChar(tc.char, tc.charset)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v, %v", code, shifted, err))
+				}
+			} else {
+				if tc.shouldFail || tc.expectCode != code || tc.expectShift != shifted {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v, %v", code, shifted, err))
+				}
+			}
+		})
+	}
+}
+
+func TestITA3Decode(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		codes      []byte
+		ignErr     bool
+		expect     string
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test decoding valid code",
+			codes:      []byte{0x15, 0x25, 0x45, 0x49, 0x4A, 0x25, 0x54},
+			ignErr:     false,
+			expect:     "X&Y",
+			shouldFail: false,
+			failedText: "expect 'X&Y', got %v",
+		},
+		{
+			caseName:   "test decoding mutilated code",
+			codes:      []byte{0x15, 0x25, 0x45, 0x49, 0x4B, 0x25, 0x54},
+			ignErr:     false,
+			expect:     "",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test decoding mutilated code, ignore error",
+			codes:      []byte{0x15, 0x25, 0x45, 0x49, 0x4B, 0x25, 0x54},
+			ignErr:     true,
+			expect:     "XY",
+			shouldFail: false,
+			failedText: "expect 'XY', got %v",
+		},
+	}
+
+	c := NewITA3(false)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			c.ignErr = tc.ignErr
+			str, err := c.Decode(tc.codes)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", str, err))
+				}
+			} else {
+				if tc.shouldFail || tc.expect != str {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", str, err))
+				}
+			}
+		})
+	}
+}
+
+func TestITA3Transcode(t *testing.T) {
+	ita2Codes, _ := NewITA2(false).Encode("RYRY 1234")
+	ita3Codes, err := ITA2ToITA3(ita2Codes)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	back, err := ITA3ToITA2(ita3Codes)
+	if err != nil || string(back) != string(ita2Codes) {
+		t.Errorf("expect %v, got %v, %v", ita2Codes, back, err)
+	}
+
+	if _, err := ITA3ToITA2([]byte{RQ_ITA3}); err == nil {
+		t.Errorf("expect an error for service signal")
+	}
+	if _, err := ITA2ToITA3([]byte{32}); err == nil {
+		t.Errorf("expect an error for invalid ITA2 code")
+	}
+}
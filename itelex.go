@@ -0,0 +1,559 @@
+/*
+ * i-Telex protocol, the TCP protocol of the hobbyist telex network. Every packet is a type byte, a length byte and the payload:
+ *
+ *	Heartbeat    0x00  no payload, keeps an idle connection alive
+ *	Direct Dial  0x01  the extension called
+ *	Baudot Data  0x02  1 to 50 ITA2 codes
+ *	End          0x03  no payload, answered with End before the connection is closed
+ *	Reject       0x04  the reason in ASCII, e.g. "occ"
+ *	Acknowledge  0x06  the number of characters printed by the receiver, modulo 256
+ *	Version      0x07  the protocol version, sent by both sides when the connection opens
+ *
+ * The sender keeps at most Window characters unprinted at the receiver, the receiver acknowledges the characters as the application reads them.
+ * Text is sent and received in ITA2, the register is kept across calls on both directions.
+ * Send and SendCodes may be called from several goroutines, the codes of each call are sent together.
+ */
+
+package baudot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+type ITelexPacketType byte
+
+const (
+	ITelexHeartbeat   ITelexPacketType = 0x00
+	ITelexDirectDial  ITelexPacketType = 0x01
+	ITelexBaudotData  ITelexPacketType = 0x02
+	ITelexEnd         ITelexPacketType = 0x03
+	ITelexReject      ITelexPacketType = 0x04
+	ITelexAcknowledge ITelexPacketType = 0x06
+	ITelexVersion     ITelexPacketType = 0x07
+)
+
+const (
+	itelexVersion        = 1
+	maxITelexBaudotCodes = 50
+	defaultITelexWindow  = 64
+	defaultITelexBeat    = 5 * time.Second
+	minITelexBeat        = 10 * time.Millisecond
+	defaultITelexTimeout = 30 * time.Second
+)
+
+type ITelexPacket struct {
+	Type ITelexPacketType
+	Data []byte
+}
+
+type ITelexOptions struct {
+	// Window is the most characters unprinted at the receiver, 0 means 64
+	Window int
+	// Heartbeat is sent when nothing else was sent for this long, 0 means 5 seconds, shorter than 10ms means 10ms
+	Heartbeat time.Duration
+	// Timeout closes the connection when nothing is received for this long, 0 means 30 seconds
+	Timeout time.Duration
+}
+
+type itelexConn struct {
+	conn    net.Conn
+	options ITelexOptions
+
+	writeMu  sync.Mutex
+	lastSent time.Time
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// codes received and not read yet
+	received []byte
+	// characters read by the application, modulo 256 like the acknowledgements
+	printed byte
+	// characters sent and acknowledged by the remote station, modulo 256
+	sent  byte
+	acked byte
+	// extension dialed by the remote station, -1 if none
+	extension     int
+	remoteVersion int
+	// err is why the connection ended, io.EOF after End
+	err     error
+	endSent bool
+	done    chan struct{}
+
+	// sendMu serializes the senders, it guards the register of the remote station
+	sendMu      sync.Mutex
+	sendStarted bool
+	sendCharset Charset
+	recvCharset Charset
+}
+
+type itelexListener struct {
+	l       net.Listener
+	options ITelexOptions
+}
+
+// MarshalBinary encodes the packet, the payload length must suit the type
+func (p ITelexPacket) MarshalBinary() ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(p.Type), byte(len(p.Data))}, p.Data...), nil
+}
+
+func (p ITelexPacket) validate() error {
+	if len(p.Data) > 0xFF {
+		return fmt.Errorf("Invalid packet: %d bytes", len(p.Data))
+	}
+
+	valid := true
+	switch p.Type {
+	case ITelexHeartbeat, ITelexEnd:
+		valid = len(p.Data) == 0
+	case ITelexDirectDial, ITelexAcknowledge:
+		valid = len(p.Data) == 1
+	case ITelexVersion:
+		valid = len(p.Data) >= 1
+	case ITelexBaudotData:
+		valid = len(p.Data) >= 1 && len(p.Data) <= maxITelexBaudotCodes
+		for _, code := range p.Data {
+			if code >= 32 {
+				return fmt.Errorf("Invalid Code: %d", code)
+			}
+		}
+	}
+	if !valid {
+		return fmt.Errorf("Invalid packet: type %d with %d bytes", p.Type, len(p.Data))
+	}
+
+	return nil
+}
+
+// ReadITelexPacket reads a packet, packets of unknown types are returned as they are
+func ReadITelexPacket(r io.Reader) (ITelexPacket, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return ITelexPacket{}, err
+	}
+	p := ITelexPacket{Type: ITelexPacketType(header[0]), Data: make([]byte, header[1])}
+	if _, err := io.ReadFull(r, p.Data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return ITelexPacket{}, err
+	}
+
+	return p, p.validate()
+}
+
+// WriteITelexPacket writes a packet
+func WriteITelexPacket(w io.Writer, p ITelexPacket) error {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+
+	return err
+}
+
+// DialITelex calls an i-Telex station, with a direct dial of the extension unless it's negative
+func DialITelex(addr string, extension int, options ITelexOptions) (*itelexConn, error) {
+	if extension > 0xFF {
+		return nil, fmt.Errorf("Invalid extension: %d", extension)
+	}
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newITelexConn(conn, options)
+	if err := c.writePacket(ITelexPacket{Type: ITelexVersion, Data: []byte{itelexVersion}}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if extension >= 0 {
+		if err := c.writePacket(ITelexPacket{Type: ITelexDirectDial, Data: []byte{byte(extension)}}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	c.start()
+
+	return c, nil
+}
+
+// ListenITelex listens for i-Telex calls on the TCP address
+func ListenITelex(addr string, options ITelexOptions) (*itelexListener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewITelexListener(l, options), nil
+}
+
+// NewITelexListener accepts i-Telex calls on a listener
+func NewITelexListener(l net.Listener, options ITelexOptions) *itelexListener {
+	return &itelexListener{l: l, options: options}
+}
+
+// Accept waits for a call, the extension dialed is known once the first packets are read(see Extension)
+func (l *itelexListener) Accept() (*itelexConn, error) {
+	conn, err := l.l.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	c := newITelexConn(conn, l.options)
+	if err := c.writePacket(ITelexPacket{Type: ITelexVersion, Data: []byte{itelexVersion}}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c.start()
+
+	return c, nil
+}
+
+func (l *itelexListener) Addr() net.Addr {
+	return l.l.Addr()
+}
+
+func (l *itelexListener) Close() error {
+	return l.l.Close()
+}
+
+func newITelexConn(conn net.Conn, options ITelexOptions) *itelexConn {
+	if options.Window <= 0 || options.Window > 0xFF {
+		options.Window = defaultITelexWindow
+	}
+	if options.Heartbeat <= 0 {
+		options.Heartbeat = defaultITelexBeat
+	}
+	if options.Heartbeat < minITelexBeat {
+		options.Heartbeat = minITelexBeat
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultITelexTimeout
+	}
+
+	c := &itelexConn{
+		conn:      conn,
+		options:   options,
+		extension: -1,
+		done:      make(chan struct{}),
+	}
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+func (c *itelexConn) start() {
+	go c.readLoop()
+	go c.heartbeat()
+}
+
+// Extension returns the extension dialed by the remote station, -1 if none was dialed so far
+func (c *itelexConn) Extension() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.extension
+}
+
+// RemoteVersion returns the protocol version of the remote station, 0 until its Version packet is read
+func (c *itelexConn) RemoteVersion() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.remoteVersion
+}
+
+// Send encodes text in ITA2 and sends it, the register of the remote station is only updated once the codes are sent
+func (c *itelexConn) Send(text string) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	var codes []byte
+	charset := c.sendCharset
+	if !c.sendStarted {
+		codes = startCodes(versionITA2, Letters)
+		charset = Letters
+	}
+	for _, char := range text {
+		code, shiftedCharset, err := encodeChar(char, charset, versionITA2)
+		if err != nil {
+			return err
+		}
+		if shiftedCharset != charset {
+			shifters, err := shiftPath(versionITA2, charset, shiftedCharset)
+			if err != nil {
+				return err
+			}
+			codes = append(codes, shifters...)
+			charset = shiftedCharset
+		}
+		codes = append(codes, code)
+	}
+
+	if err := c.sendCodes(codes); err != nil {
+		return err
+	}
+	c.sendStarted = true
+	c.sendCharset = charset
+
+	return nil
+}
+
+// SendCodes sends ITA2 codes, it waits while the remote station has Window characters to print
+func (c *itelexConn) SendCodes(codes []byte) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if err := c.sendCodes(codes); err != nil {
+		return err
+	}
+	// follow the shift codes, so Send goes on in the register the remote station is in
+	for _, code := range codes {
+		if _, shiftedCharset, err := decodeChar(code, c.sendCharset, versionITA2); err == nil {
+			c.sendCharset = shiftedCharset
+		}
+	}
+
+	return nil
+}
+
+func (c *itelexConn) sendCodes(codes []byte) error {
+	for _, code := range codes {
+		if code >= 32 {
+			return fmt.Errorf("Invalid Code: %d", code)
+		}
+	}
+
+	for len(codes) > 0 {
+		c.mu.Lock()
+		for c.err == nil && int(c.sent-c.acked) >= c.options.Window {
+			c.cond.Wait()
+		}
+		if c.err != nil {
+			err := c.err
+			c.mu.Unlock()
+			return err
+		}
+		n := min(c.options.Window-int(c.sent-c.acked), maxITelexBaudotCodes, len(codes))
+		c.sent += byte(n)
+		c.mu.Unlock()
+
+		if err := c.writePacket(ITelexPacket{Type: ITelexBaudotData, Data: codes[:n]}); err != nil {
+			c.fail(err)
+			return err
+		}
+		codes = codes[n:]
+	}
+
+	return nil
+}
+
+// Receive waits for codes and decodes them, it returns io.EOF once the remote station ended the connection
+func (c *itelexConn) Receive() (string, error) {
+	codes, err := c.ReceiveCodes()
+	if err != nil {
+		return "", err
+	}
+
+	var str []rune
+	for _, code := range codes {
+		char, shiftedCharset, err := decodeChar(code, c.recvCharset, versionITA2)
+		if err != nil {
+			continue
+		}
+		if shiftedCharset != c.recvCharset {
+			c.recvCharset = shiftedCharset
+			continue
+		}
+		if char != '\u0000' {
+			str = append(str, char)
+		}
+	}
+
+	return string(str), nil
+}
+
+// ReceiveCodes waits for codes, the codes returned are acknowledged as printed
+func (c *itelexConn) ReceiveCodes() ([]byte, error) {
+	c.mu.Lock()
+	for len(c.received) == 0 && c.err == nil {
+		c.cond.Wait()
+	}
+	if len(c.received) == 0 {
+		err := c.err
+		c.mu.Unlock()
+		return nil, err
+	}
+	codes := c.received
+	c.received = nil
+	c.printed += byte(len(codes))
+	printed := c.printed
+	ended := c.err != nil
+	c.mu.Unlock()
+
+	if !ended {
+		if err := c.writePacket(ITelexPacket{Type: ITelexAcknowledge, Data: []byte{printed}}); err != nil {
+			c.fail(err)
+		}
+	}
+
+	return codes, nil
+}
+
+// Reject refuses the call with a reason, e.g. "occ" when busy
+func (c *itelexConn) Reject(reason string) error {
+	if len(reason) > 0xFF {
+		return fmt.Errorf("Invalid reason: %s", reason)
+	}
+	err := c.writePacket(ITelexPacket{Type: ITelexReject, Data: []byte(reason)})
+	c.fail(fmt.Errorf("Rejected: %s", reason))
+
+	return err
+}
+
+// Close ends the connection, it sends End and waits for the End of the remote station, the error is why the connection ended otherwise,
+// e.g. a timeout
+func (c *itelexConn) Close() error {
+	c.mu.Lock()
+	if c.err != nil || c.endSent {
+		defer c.mu.Unlock()
+		return c.closeErr()
+	}
+	c.endSent = true
+	c.mu.Unlock()
+
+	if err := c.writePacket(ITelexPacket{Type: ITelexEnd}); err != nil {
+		c.fail(err)
+		return err
+	}
+	select {
+	case <-c.done:
+	case <-time.After(c.options.Timeout):
+		c.fail(fmt.Errorf("Timeout"))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closeErr()
+}
+
+// closeErr returns the error the connection failed with, io.EOF is the End of the remote station
+func (c *itelexConn) closeErr() error {
+	if c.err != io.EOF {
+		return c.err
+	}
+
+	return nil
+}
+
+func (c *itelexConn) readLoop() {
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(c.options.Timeout))
+		p, err := ReadITelexPacket(c.conn)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				err = fmt.Errorf("Timeout")
+			}
+			c.fail(err)
+			return
+		}
+		if !c.handle(p) {
+			return
+		}
+	}
+}
+
+// handle updates the connection with a packet, it returns false once the connection ended
+func (c *itelexConn) handle(p ITelexPacket) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch p.Type {
+	case ITelexDirectDial:
+		c.extension = int(p.Data[0])
+	case ITelexBaudotData:
+		c.received = append(c.received, p.Data...)
+		c.cond.Broadcast()
+	case ITelexAcknowledge:
+		c.acked = p.Data[0]
+		c.cond.Broadcast()
+	case ITelexVersion:
+		c.remoteVersion = int(p.Data[0])
+	case ITelexEnd:
+		if !c.endSent {
+			c.endSent = true
+			c.mu.Unlock()
+			c.writePacket(ITelexPacket{Type: ITelexEnd})
+			c.mu.Lock()
+		}
+		c.end(io.EOF)
+		return false
+	case ITelexReject:
+		c.end(fmt.Errorf("Rejected: %s", p.Data))
+		return false
+	}
+
+	return true
+}
+
+// heartbeat sends Heartbeat when nothing was sent for a while
+func (c *itelexConn) heartbeat() {
+	ticker := time.NewTicker(c.options.Heartbeat / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.writeMu.Lock()
+			idle := time.Since(c.lastSent) >= c.options.Heartbeat
+			c.writeMu.Unlock()
+			if idle {
+				if err := c.writePacket(ITelexPacket{Type: ITelexHeartbeat}); err != nil {
+					c.fail(err)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *itelexConn) writePacket(p ITelexPacket) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.lastSent = time.Now()
+
+	return WriteITelexPacket(c.conn, p)
+}
+
+func (c *itelexConn) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.end(err)
+}
+
+// end ends the connection with the reason, the first reason is kept
+func (c *itelexConn) end(err error) {
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	c.cond.Broadcast()
+	close(c.done)
+	c.conn.Close()
+}
@@ -0,0 +1,326 @@
+package baudot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestITelexPacket(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		packet     ITelexPacket
+		expect     []byte
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test heartbeat",
+			packet:     ITelexPacket{Type: ITelexHeartbeat},
+			expect:     []byte{0x00, 0},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0x00, 0}),
+		},
+		{
+			caseName:   "test direct dial",
+			packet:     ITelexPacket{Type: ITelexDirectDial, Data: []byte{12}},
+			expect:     []byte{0x01, 1, 12},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0x01, 1, 12}),
+		},
+		{
+			caseName:   "test baudot data",
+			packet:     ITelexPacket{Type: ITelexBaudotData, Data: []byte{31, 20, 1}},
+			expect:     []byte{0x02, 3, 31, 20, 1},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0x02, 3, 31, 20, 1}),
+		},
+		{
+			caseName:   "test end",
+			packet:     ITelexPacket{Type: ITelexEnd},
+			expect:     []byte{0x03, 0},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0x03, 0}),
+		},
+		{
+			caseName:   "test reject",
+			packet:     ITelexPacket{Type: ITelexReject, Data: []byte("occ")},
+			expect:     []byte{0x04, 3, 'o', 'c', 'c'},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0x04, 3, 'o', 'c', 'c'}),
+		},
+		{
+			caseName:   "test acknowledge",
+			packet:     ITelexPacket{Type: ITelexAcknowledge, Data: []byte{200}},
+			expect:     []byte{0x06, 1, 200},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0x06, 1, 200}),
+		},
+		{
+			caseName:   "test empty baudot data",
+			packet:     ITelexPacket{Type: ITelexBaudotData},
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test long baudot data",
+			packet:     ITelexPacket{Type: ITelexBaudotData, Data: make([]byte, 51)},
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test invalid code",
+			packet:     ITelexPacket{Type: ITelexBaudotData, Data: []byte{32}},
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test heartbeat with payload",
+			packet:     ITelexPacket{Type: ITelexHeartbeat, Data: []byte{1}},
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			data, err := tc.packet.MarshalBinary()
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, err)
+				}
+				return
+			}
+			if tc.shouldFail || !bytes.Equal(data, tc.expect) {
+				t.Fatalf(tc.failedText, data)
+			}
+
+			p, err := ReadITelexPacket(bytes.NewReader(data))
+			if err != nil || p.Type != tc.packet.Type || !bytes.Equal(p.Data, tc.packet.Data) {
+				t.Errorf("expect %v read, got %v, %v", tc.packet, p, err)
+			}
+		})
+	}
+
+	if _, err := ReadITelexPacket(bytes.NewReader([]byte{0x02, 3, 1})); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expect a truncated packet, got %v", err)
+	}
+	if p, err := ReadITelexPacket(bytes.NewReader([]byte{0x20, 1, 7})); err != nil || p.Type != 0x20 {
+		t.Errorf("expect an unknown packet read as it is, got %v, %v", p, err)
+	}
+}
+
+// itelexPair connects a client to a server, the server side is returned second
+func itelexPair(t *testing.T, extension int, options ITelexOptions) (*itelexConn, *itelexConn) {
+	t.Helper()
+
+	l, err := ListenITelex("127.0.0.1:0", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan *itelexConn, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			t.Error(err)
+		}
+		accepted <- c
+	}()
+
+	client, err := DialITelex(l.Addr().String(), extension, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := <-accepted
+	if server == nil {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		client.fail(io.EOF)
+		server.fail(io.EOF)
+	})
+
+	return client, server
+}
+
+// receiveText reads until n characters are received
+func receiveText(t *testing.T, c *itelexConn, n int) string {
+	t.Helper()
+
+	var text string
+	for len([]rune(text)) < n {
+		str, err := c.Receive()
+		if err != nil {
+			t.Fatalf("expect %d characters, got %q, %v", n, text, err)
+		}
+		text += str
+	}
+
+	return text
+}
+
+func TestITelexConnection(t *testing.T) {
+	client, server := itelexPair(t, 12, ITelexOptions{})
+
+	if err := client.Send("RYRY 1234"); err != nil {
+		t.Fatal(err)
+	}
+	if text := receiveText(t, server, 9); text != "RYRY 1234" {
+		t.Errorf("expect %q, got %q", "RYRY 1234", text)
+	}
+	if server.Extension() != 12 || server.RemoteVersion() != 1 {
+		t.Errorf("expect extension 12 and version 1, got %d and %d", server.Extension(), server.RemoteVersion())
+	}
+
+	// the register is kept between sends
+	server.Send("5")
+	server.Send("6 OK")
+	if text := receiveText(t, client, 5); text != "56 OK" {
+		t.Errorf("expect %q, got %q", "56 OK", text)
+	}
+	if client.Extension() != -1 {
+		t.Errorf("expect no extension dialed by the server, got %d", client.Extension())
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Receive(); err != io.EOF {
+		t.Errorf("expect io.EOF after End, got %v", err)
+	}
+	if err := client.Send("A"); err != io.EOF {
+		t.Errorf("expect io.EOF sending after End, got %v", err)
+	}
+}
+
+func TestITelexSendError(t *testing.T) {
+	client, server := itelexPair(t, -1, ITelexOptions{})
+
+	// a failed send sends nothing and leaves the register as it was
+	if err := client.Send("1$"); err == nil {
+		t.Fatalf("expect an error for '$'")
+	}
+	if err := client.Send("2"); err != nil {
+		t.Fatal(err)
+	}
+	if text := receiveText(t, server, 1); text != "2" {
+		t.Errorf("expect %q, got %q", "2", text)
+	}
+
+	// the register follows the shift codes sent as codes
+	if err := client.SendCodes([]byte{FS}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Send("A"); err != nil {
+		t.Fatal(err)
+	}
+	if text := receiveText(t, server, 1); text != "A" {
+		t.Errorf("expect %q, got %q", "A", text)
+	}
+
+	// concurrent senders don't mix their registers
+	sent := make(chan error, 2)
+	go func() { sent <- client.Send("1111") }()
+	go func() { sent <- client.Send("AAAA") }()
+	for i := 0; i < 2; i++ {
+		if err := <-sent; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if text := receiveText(t, server, 8); text != "1111AAAA" && text != "AAAA1111" {
+		t.Errorf("expect both messages, got %q", text)
+	}
+}
+
+func TestITelexFlowControl(t *testing.T) {
+	client, server := itelexPair(t, -1, ITelexOptions{Window: 10})
+
+	msg := strings.Repeat("THE QUICK BROWN FOX ", 10)
+	sent := make(chan error, 1)
+	go func() { sent <- client.Send(msg) }()
+
+	// the sender stops at the window until the receiver prints
+	time.Sleep(50 * time.Millisecond)
+	server.mu.Lock()
+	buffered := len(server.received)
+	server.mu.Unlock()
+	if buffered == 0 || buffered > 10 {
+		t.Errorf("expect at most 10 codes buffered, got %d", buffered)
+	}
+	select {
+	case err := <-sent:
+		t.Fatalf("expect the sender waiting, got %v", err)
+	default:
+	}
+
+	if text := receiveText(t, server, len(msg)); text != msg {
+		t.Errorf("expect %q, got %q", msg, text)
+	}
+	if err := <-sent; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestITelexReject(t *testing.T) {
+	client, server := itelexPair(t, 3, ITelexOptions{})
+
+	if err := server.Reject("occ"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Receive(); err == nil || err.Error() != "Rejected: occ" {
+		t.Errorf("expect the call rejected, got %v", err)
+	}
+}
+
+func TestITelexHeartbeat(t *testing.T) {
+	client, server := itelexPair(t, -1, ITelexOptions{Heartbeat: 20 * time.Millisecond, Timeout: 100 * time.Millisecond})
+
+	// heartbeats keep the idle connection alive past the timeout
+	time.Sleep(300 * time.Millisecond)
+	if err := client.Send("A"); err != nil {
+		t.Fatal(err)
+	}
+	if text := receiveText(t, server, 1); text != "A" {
+		t.Errorf("expect %q, got %q", "A", text)
+	}
+
+	// a silent station times out
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+	silent, err := DialITelex(l.Addr().String(), -1, ITelexOptions{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := silent.Receive(); err == nil || err.Error() != "Timeout" {
+		t.Errorf("expect a timeout, got %v", err)
+	}
+	if err := silent.Close(); err == nil || err.Error() != "Timeout" {
+		t.Errorf("expect the timeout closing a failed connection, got %v", err)
+	}
+
+	// End is never answered
+	silent, err = DialITelex(l.Addr().String(), -1, ITelexOptions{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := silent.Close(); err == nil || err.Error() != "Timeout" {
+		t.Errorf("expect a timeout closing, got %v", err)
+	}
+
+	// a heartbeat too short for a ticker is raised to the minimum
+	client, _ = itelexPair(t, -1, ITelexOptions{Heartbeat: time.Nanosecond})
+	if client.options.Heartbeat != minITelexBeat {
+		t.Errorf("expect a heartbeat of %v, got %v", minITelexBeat, client.options.Heartbeat)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ * Japanese katakana teleprinter code, ITA2 with a katakana register selected by KS(the null code).
+ * A 5 bit register has room for 26 kana besides space, CR and LF, so the katakana register has 2 halves:
+ * KS from letters or figures selects the first half(ア to ハ) and KS within the register switches halves(ヒ to ン and the marks).
+ * Voiced kana are sent as the kana followed by the ゛ or ゜ mark and decode back to the precomposed kana,
+ * small kana are sent as the full size kana like in telegrams.
+ */
+
+package baudot
+
+import "strings"
+
+// the voiced and semi-voiced kana with the kana and mark they are sent as
+var voicedKatakana = map[rune][2]rune{}
+
+func init() {
+	for _, base := range "カキクケコサシスセソタチツテトハヒフヘホ" {
+		voicedKatakana[base+1] = [2]rune{base, '゛'}
+	}
+	for _, base := range "ハヒフヘホ" {
+		voicedKatakana[base+2] = [2]rune{base, '゜'}
+	}
+	voicedKatakana['ヴ'] = [2]rune{'ウ', '゛'}
+}
+
+var smallKatakana = strings.NewReplacer(
+	"ァ", "ア", "ィ", "イ", "ゥ", "ウ", "ェ", "エ", "ォ", "オ",
+	"ッ", "ツ", "ャ", "ヤ", "ュ", "ユ", "ョ", "ヨ", "ヮ", "ワ",
+)
+
+func katakanaTables() *tables {
+	letters := map[byte]rune{}
+	figures := map[byte]rune{}
+	for code, char := range lettersITA2 {
+		if code != KS {
+			letters[code] = char
+		}
+	}
+	for code, char := range figuresITA2 {
+		if code != KS {
+			figures[code] = char
+		}
+	}
+
+	t := &tables{
+		name:      "Katakana",
+		names:     []string{"letters", "figures", "katakana", "katakana extended"},
+		registers: []map[byte]rune{letters, figures, kanaKatakana, kanaKatakanaExtended},
+		shifts: []map[byte]Charset{
+			{LS: Letters, FS: Figures, KS: Katakana},
+			{LS: Letters, FS: Figures, KS: Katakana},
+			{LS: Letters, FS: Figures, KS: KatakanaExtended},
+			{LS: Letters, FS: Figures, KS: Katakana},
+		},
+	}
+	t.charmap = charmapOf(t.registers)
+
+	return t
+}
+
+// Encode string into byte array represent the sequence of katakana teleprinter code
+func (c *katakana) Encode(msg string) ([]byte, error) {
+	codes, err := encode(c.normalize(msg), c.ignErr, versionKatakana)
+	if err != nil {
+		return nil, unnormalizedPosition(err, msg, versionKatakana, c.normalize)
+	}
+
+	return codes, nil
+}
+
+// Decode katakana teleprinter code to string
+func (c *katakana) Decode(codes []byte) (string, error) {
+	str, err := decode(codes, c.ignErr, versionKatakana)
+
+	return composeKatakana(str), err
+}
+
+// EncodeChar encodes a character into katakana teleprinter code, voiced kana take 2 codes so they must be encoded as the kana and the mark
+func (c *katakana) EncodeChar(char rune, currentCharset Charset) (byte, Charset, error) {
+	return encodeChar(char, currentCharset, versionKatakana)
+}
+
+// DecodeChar decodes a katakana teleprinter code to rune, returns the register the receiver is in after the code
+func (c *katakana) DecodeChar(code byte, currentCharset Charset) (rune, Charset, error) {
+	return decodeChar(code, currentCharset, versionKatakana)
+}
+
+func (c *katakana) ver() version {
+	return versionKatakana
+}
+
+// Repertoire returns the characters of a register in code order
+func (c *katakana) Repertoire(charset Charset) []rune {
+	return repertoire(versionKatakana, charset)
+}
+
+// CanEncode reports whether every character of msg is in katakana teleprinter code, voiced and small kana included
+func (c *katakana) CanEncode(msg string) bool {
+	return len(c.Unsupported(msg)) == 0
+}
+
+// Unsupported returns the characters of msg which are not in katakana teleprinter code
+func (c *katakana) Unsupported(msg string) []UnsupportedChar {
+	return unsupported(msg, versionKatakana, c.normalize)
+}
+
+// EncodedLen returns the number of codes Encode returns for msg, shift codes included
+func (c *katakana) EncodedLen(msg string) (int, error) {
+	return encodedLen(c.normalize(msg), c.ignErr, versionKatakana)
+}
+
+// normalize decomposes voiced kana and replaces small kana
+func (c *katakana) normalize(msg string) string {
+	var sb strings.Builder
+	for _, char := range smallKatakana.Replace(msg) {
+		if pair, ok := voicedKatakana[char]; ok {
+			sb.WriteRune(pair[0])
+			sb.WriteRune(pair[1])
+		} else {
+			sb.WriteRune(char)
+		}
+	}
+
+	return sb.String()
+}
+
+func composeKatakana(str string) string {
+	composed := map[[2]rune]rune{}
+	for voiced, pair := range voicedKatakana {
+		composed[pair] = voiced
+	}
+
+	chars := []rune(str)
+	var result []rune
+	for i := 0; i < len(chars); i++ {
+		if i+1 < len(chars) {
+			if voiced, ok := composed[[2]rune{chars[i], chars[i+1]}]; ok {
+				result = append(result, voiced)
+				i++
+				continue
+			}
+		}
+		result = append(result, chars[i])
+	}
+
+	return string(result)
+}
@@ -0,0 +1,128 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestKatakanaEncode(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		msg        string
+		expect     []byte
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test first half",
+			msg:        "アサ",
+			expect:     []byte{31, 0, 1, 14},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{31, 0, 1, 14}),
+		},
+		{
+			caseName:   "test both halves",
+			msg:        "カメラ",
+			expect:     []byte{31, 0, 9, 0, 11, 16},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{31, 0, 9, 0, 11, 16}),
+		},
+		{
+			caseName:   "test voiced kana",
+			msg:        "ガス",
+			expect:     []byte{31, 0, 9, 0, 24, 0, 16},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{31, 0, 9, 0, 24, 0, 16}),
+		},
+		{
+			caseName:   "test small kana",
+			msg:        "キッテ",
+			expect:     []byte{31, 0, 10, 21, 22},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{31, 0, 10, 21, 22}),
+		},
+		{
+			caseName:   "test letters figures and kana",
+			msg:        "TOKYO 3 ハ",
+			expect:     []byte{31, 16, 24, 15, 21, 24, 4, 27, 1, 4, 0, 30},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{31, 16, 24, 15, 21, 24, 4, 27, 1, 4, 0, 30}),
+		},
+		{
+			caseName:   "test kanji",
+			msg:        "東京",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	c := NewKatakana(false)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			codes, err := c.Encode(tc.msg)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+				}
+			} else if tc.shouldFail || string(codes) != string(tc.expect) {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+			}
+		})
+	}
+}
+
+func TestKatakanaDecode(t *testing.T) {
+	tt := []struct {
+		caseName string
+		codes    []byte
+		expect   string
+	}{
+		{caseName: "test halves", codes: []byte{31, 0, 9, 0, 11, 16}, expect: "カメラ"},
+		{caseName: "test voiced kana", codes: []byte{31, 0, 30, 0, 25, 4, 0, 9, 0, 24}, expect: "パ ガ"},
+		{caseName: "test mark alone", codes: []byte{31, 0, 0, 24}, expect: "゛"},
+		{caseName: "test back to letters", codes: []byte{31, 0, 1, 31, 1, 27, 1}, expect: "アE3"},
+	}
+
+	c := NewKatakana(false)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			str, err := c.Decode(tc.codes)
+			if err != nil || str != tc.expect {
+				t.Errorf("expect %q, got %q, %v", tc.expect, str, err)
+			}
+		})
+	}
+}
+
+func TestKatakanaRoundTrip(t *testing.T) {
+	c := NewKatakana(false)
+	msg := "ニホン ノ テレタイプ ハ ガイコク ノ モノ ト チガウ。"
+	codes, err := c.Encode(msg)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	str, err := c.Decode(codes)
+	if err != nil || str != msg {
+		t.Errorf("expect %q, got %q, %v", msg, str, err)
+	}
+
+	optimized, err := EncodeOptimized(c, msg, OptimizeOptions{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if str, _ := c.Decode(optimized); str != msg || len(optimized) > len(codes) {
+		t.Errorf("expect %q in at most %d codes, got %q in %d codes", msg, len(codes), str, len(optimized))
+	}
+}
+
+func TestKatakanaChar(t *testing.T) {
+	c := NewKatakana(false)
+	code, charset, err := c.EncodeChar('ン', Letters)
+	if err != nil || code != 23 || charset != KatakanaExtended {
+		t.Errorf("expect 23 in KatakanaExtended, got %v, %v, %v", code, charset, err)
+	}
+
+	char, charset, err := c.DecodeChar(KS, Katakana)
+	if err != nil || char != '\u0000' || charset != KatakanaExtended {
+		t.Errorf("expect shift to KatakanaExtended, got %q, %v, %v", char, charset, err)
+	}
+	char, charset, err = c.DecodeChar(KS, KatakanaExtended)
+	if err != nil || char != '\u0000' || charset != Katakana {
+		t.Errorf("expect shift to Katakana, got %q, %v, %v", char, charset, err)
+	}
+}
@@ -0,0 +1,321 @@
+/*
+ * Message is a sequence of codes with the variant it's encoded in, for storing and exchanging messages.
+ * The variant is identified by its name(e.g. "ITA2", "ITA2 German"), variants loaded at runtime must be loaded again before reading their messages.
+ *
+ * Representations:
+ *	text:   variant name, a colon and the codes in hex, e.g. "ITA2:1f1401", also the database value
+ *	JSON:   {"variant": "ITA2", "text": "HE"}, with "hex": "1f1401" or "bits": "11111 10100 00001" instead of the text as chosen by Format
+ *	binary: length of the variant name, the name, the number of codes as uvarint and the codes packed in 5 bits, most significant bit first
+ * The text of the JSON representation is encoded again when read, redundant shift codes are not kept.
+ */
+
+package baudot
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type MessageFormat byte
+
+const (
+	// the JSON representation holds the decoded text
+	FormatText MessageFormat = 0
+	// the JSON representation holds the codes in hex
+	FormatHex MessageFormat = 1
+	// the JSON representation holds the codes as 5 bit strings, most significant bit first
+	FormatBits MessageFormat = 2
+)
+
+type Message struct {
+	Variant string
+	Codes   []byte
+	// Format of the JSON representation
+	Format MessageFormat
+}
+
+type messageJSON struct {
+	Variant string  `json:"variant"`
+	Text    *string `json:"text,omitempty"`
+	Hex     *string `json:"hex,omitempty"`
+	Bits    *string `json:"bits,omitempty"`
+}
+
+// NewMessage encodes msg with the codec
+func NewMessage(codec Codec, msg string) (*Message, error) {
+	v, ok := codec.(versioned)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported codec: %T", codec)
+	}
+	t, err := lookupTables(v.ver())
+	if err != nil {
+		return nil, err
+	}
+	codes, err := codec.Encode(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{Variant: t.name, Codes: codes}, nil
+}
+
+// Codec returns the codec of the message's variant
+func (m Message) Codec(ignoreError bool) (Codec, error) {
+	ver, err := lookupName(m.Variant)
+	if err != nil {
+		return nil, err
+	}
+
+	return codecOf(ver, ignoreError), nil
+}
+
+// Text decodes the message
+func (m Message) Text() (string, error) {
+	codec, err := m.Codec(false)
+	if err != nil {
+		return "", err
+	}
+
+	return codec.Decode(m.Codes)
+}
+
+func (m Message) MarshalText() ([]byte, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+
+	return []byte(m.Variant + ":" + hex.EncodeToString(m.Codes)), nil
+}
+
+func (m *Message) UnmarshalText(text []byte) error {
+	i := strings.LastIndexByte(string(text), ':')
+	if i < 0 {
+		return fmt.Errorf("Invalid message: %q", text)
+	}
+	codes, err := hex.DecodeString(string(text[i+1:]))
+	if err != nil {
+		return fmt.Errorf("Invalid message: %v", err)
+	}
+
+	msg := Message{Variant: string(text[:i]), Codes: codes, Format: m.Format}
+	if err := msg.check(); err != nil {
+		return err
+	}
+	*m = msg
+
+	return nil
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+
+	v := messageJSON{Variant: m.Variant}
+	switch m.Format {
+	case FormatText:
+		text, err := m.Text()
+		if err != nil {
+			return nil, err
+		}
+		v.Text = &text
+	case FormatHex:
+		s := hex.EncodeToString(m.Codes)
+		v.Hex = &s
+	case FormatBits:
+		bits := make([]string, len(m.Codes))
+		for i, code := range m.Codes {
+			bits[i] = fmt.Sprintf("%05b", code)
+		}
+		s := strings.Join(bits, " ")
+		v.Bits = &s
+	default:
+		return nil, fmt.Errorf("Invalid format: %d", m.Format)
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON reads any representation, Format is set to the one read
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var v messageJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	msg := Message{Variant: v.Variant}
+	switch {
+	case v.Text != nil && v.Hex == nil && v.Bits == nil:
+		codec, err := msg.Codec(false)
+		if err != nil {
+			return err
+		}
+		if msg.Codes, err = codec.Encode(*v.Text); err != nil {
+			return err
+		}
+		msg.Format = FormatText
+	case v.Hex != nil && v.Text == nil && v.Bits == nil:
+		codes, err := hex.DecodeString(*v.Hex)
+		if err != nil {
+			return fmt.Errorf("Invalid message: %v", err)
+		}
+		msg.Codes = codes
+		msg.Format = FormatHex
+	case v.Bits != nil && v.Text == nil && v.Hex == nil:
+		for _, field := range strings.Fields(*v.Bits) {
+			code, err := strconv.ParseUint(field, 2, 5)
+			if err != nil || len(field) != 5 {
+				return fmt.Errorf("Invalid Code: %q", field)
+			}
+			msg.Codes = append(msg.Codes, byte(code))
+		}
+		msg.Format = FormatBits
+	default:
+		return fmt.Errorf("Invalid message: expect one of text, hex and bits")
+	}
+	if err := msg.check(); err != nil {
+		return err
+	}
+	*m = msg
+
+	return nil
+}
+
+func (m Message) MarshalBinary() ([]byte, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	if len(m.Variant) > 255 {
+		return nil, fmt.Errorf("Invalid variant: %s", m.Variant)
+	}
+
+	data := append([]byte{byte(len(m.Variant))}, m.Variant...)
+	data = binary.AppendUvarint(data, uint64(len(m.Codes)))
+	packed := make([]byte, (len(m.Codes)*5+7)/8)
+	for i, code := range m.Codes {
+		for bit := 0; bit < 5; bit++ {
+			if code&(0x10>>bit) != 0 {
+				n := i*5 + bit
+				packed[n/8] |= 0x80 >> (n % 8)
+			}
+		}
+	}
+
+	return append(data, packed...), nil
+}
+
+func (m *Message) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || len(data) < 1+int(data[0]) {
+		return fmt.Errorf("Invalid message: too short")
+	}
+	variant := string(data[1 : 1+data[0]])
+	data = data[1+data[0]:]
+	n, size := binary.Uvarint(data)
+	if size <= 0 {
+		return fmt.Errorf("Invalid message: bad length")
+	}
+	data = data[size:]
+	if n > uint64(len(data))*8/5 || uint64(len(data)) != (n*5+7)/8 {
+		return fmt.Errorf("Invalid message: %d codes in %d bytes", n, len(data))
+	}
+
+	codes := make([]byte, n)
+	for i := range codes {
+		for bit := 0; bit < 5; bit++ {
+			n := i*5 + bit
+			if data[n/8]&(0x80>>(n%8)) != 0 {
+				codes[i] |= 0x10 >> bit
+			}
+		}
+	}
+
+	msg := Message{Variant: variant, Codes: codes, Format: m.Format}
+	if err := msg.check(); err != nil {
+		return err
+	}
+	*m = msg
+
+	return nil
+}
+
+// Value stores the text representation, the zero message is stored as NULL
+func (m Message) Value() (driver.Value, error) {
+	if m.Variant == "" && len(m.Codes) == 0 {
+		return nil, nil
+	}
+	text, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return string(text), nil
+}
+
+// Scan reads the text representation, NULL is read as the zero message
+func (m *Message) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return m.UnmarshalText([]byte(src))
+	case []byte:
+		return m.UnmarshalText(src)
+	case nil:
+		*m = Message{Format: m.Format}
+		return nil
+	}
+
+	return fmt.Errorf("Unsupported type: %T", src)
+}
+
+// check verifies the variant is known and the codes are 5 bit codes
+func (m Message) check() error {
+	if _, err := lookupName(m.Variant); err != nil {
+		return err
+	}
+	for _, code := range m.Codes {
+		if code >= 32 {
+			return fmt.Errorf("Invalid Code: %d", code)
+		}
+	}
+
+	return nil
+}
+
+// lookupName returns the version of a variant, names are unique(see registerTables)
+func lookupName(name string) (version, error) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	ver, ok := customNames[name]
+	if !ok {
+		return 0, fmt.Errorf("Unsupported variant: %s", name)
+	}
+
+	return ver, nil
+}
+
+// codecOf returns the codec of a version
+func codecOf(ver version, ignoreError bool) Codec {
+	switch ver {
+	case versionITA1:
+		return NewITA1(ignoreError)
+	case versionITA1Continental:
+		return NewITA1Continental(ignoreError)
+	case versionITA2:
+		return NewITA2(ignoreError)
+	case versionUSTTY:
+		return NewUSTTY(ignoreError)
+	case versionMurray:
+		return NewMurray(ignoreError)
+	case versionWeather:
+		return NewWeather(ignoreError)
+	case versionKatakana:
+		return NewKatakana(ignoreError)
+	}
+
+	return &variant{ignErr: ignoreError, version: ver}
+}
@@ -0,0 +1,258 @@
+package baudot
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler     = Message{}
+	_ encoding.TextUnmarshaler   = &Message{}
+	_ encoding.BinaryMarshaler   = Message{}
+	_ encoding.BinaryUnmarshaler = &Message{}
+	_ json.Marshaler             = Message{}
+	_ json.Unmarshaler           = &Message{}
+	_ driver.Valuer              = Message{}
+	_ sql.Scanner                = &Message{}
+)
+
+func TestMessageJSON(t *testing.T) {
+	german, err := NewITA2National(NationalGerman, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		caseName   string
+		codec      Codec
+		msg        string
+		format     MessageFormat
+		expect     string
+		failedText string
+	}{
+		{
+			caseName:   "test text",
+			codec:      NewITA2(false),
+			msg:        "HI 5",
+			format:     FormatText,
+			expect:     `{"variant":"ITA2","text":"HI 5"}`,
+			failedText: "expect text representation, got %v",
+		},
+		{
+			caseName:   "test hex",
+			codec:      NewITA2(false),
+			msg:        "HI",
+			format:     FormatHex,
+			expect:     `{"variant":"ITA2","hex":"001f1406"}`,
+			failedText: "expect hex representation, got %v",
+		},
+		{
+			caseName:   "test bits",
+			codec:      NewITA2(false),
+			msg:        "HI",
+			format:     FormatBits,
+			expect:     `{"variant":"ITA2","bits":"00000 11111 10100 00110"}`,
+			failedText: "expect bits representation, got %v",
+		},
+		{
+			caseName:   "test national variant",
+			codec:      german,
+			msg:        "ÄÖ",
+			format:     FormatText,
+			expect:     `{"variant":"ITA2 German","text":"ÄÖ"}`,
+			failedText: "expect the national variant kept, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			m, err := NewMessage(tc.codec, tc.msg)
+			if err != nil {
+				t.Fatalf(tc.failedText, err)
+			}
+			m.Format = tc.format
+			data, err := json.Marshal(m)
+			if err != nil || string(data) != tc.expect {
+				t.Fatalf(tc.failedText, fmt.Sprintf("%s, %v", data, err))
+			}
+
+			var got Message
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf(tc.failedText, err)
+			}
+			if got.Variant != m.Variant || string(got.Codes) != string(m.Codes) || got.Format != tc.format {
+				t.Errorf(tc.failedText, got)
+			}
+		})
+	}
+}
+
+func TestMessageUnmarshalJSON(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		data       string
+		shouldFail bool
+	}{
+		{caseName: "test unknown variant", data: `{"variant":"ITA9","hex":"00"}`, shouldFail: true},
+		{caseName: "test no representation", data: `{"variant":"ITA2"}`, shouldFail: true},
+		{caseName: "test two representations", data: `{"variant":"ITA2","hex":"00","bits":"00000"}`, shouldFail: true},
+		{caseName: "test invalid code", data: `{"variant":"ITA2","hex":"20"}`, shouldFail: true},
+		{caseName: "test short bits", data: `{"variant":"ITA2","bits":"0001"}`, shouldFail: true},
+		{caseName: "test invalid char", data: `{"variant":"ITA2","text":"@"}`, shouldFail: true},
+		{caseName: "test katakana", data: `{"variant":"Katakana","text":"ガ"}`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			var m Message
+			err := json.Unmarshal([]byte(tc.data), &m)
+			if (err != nil) != tc.shouldFail {
+				t.Errorf("expect error %v, got %v", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
+func TestMessageText(t *testing.T) {
+	m, _ := NewMessage(NewUSTTY(false), "$5 BILL")
+	text, err := m.MarshalText()
+	if err != nil || string(text) != "US TTY:001f1b0910041f19061212" {
+		t.Fatalf("expect text, got %s, %v", text, err)
+	}
+
+	var got Message
+	if err := got.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if str, err := got.Text(); err != nil || str != "$5 BILL" {
+		t.Errorf("expect %q, got %q, %v", "$5 BILL", str, err)
+	}
+
+	if err := got.UnmarshalText([]byte("ITA2")); err == nil {
+		t.Errorf("expect an error without codes")
+	}
+}
+
+func TestMessageBinary(t *testing.T) {
+	for _, msg := range []string{"", "E", "RYRY", "THE QUICK BROWN FOX 1234567890"} {
+		m, err := NewMessage(NewITA2(false), msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect := 1 + len("ITA2") + 1 + (len(m.Codes)*5+7)/8; len(data) != expect {
+			t.Errorf("expect %d bytes, got %d", expect, len(data))
+		}
+
+		var got Message
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("expect %v decoded, got %v", data, err)
+		}
+		if got.Variant != "ITA2" || string(got.Codes) != string(m.Codes) {
+			t.Errorf("expect %v, got %v", m, got)
+		}
+	}
+
+	m := Message{Variant: "ITA2", Codes: []byte{1}}
+	data, _ := m.MarshalBinary()
+	var got Message
+	if err := got.UnmarshalBinary(append(data, 0)); err == nil {
+		t.Errorf("expect an error for trailing bytes")
+	}
+	if err := got.UnmarshalBinary(data[:3]); err == nil {
+		t.Errorf("expect an error for a truncated message")
+	}
+}
+
+func TestMessageSQL(t *testing.T) {
+	m, _ := NewMessage(NewMurray(false), "½")
+	value, err := m.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		caseName   string
+		src        any
+		shouldFail bool
+	}{
+		{caseName: "test string", src: value},
+		{caseName: "test bytes", src: []byte(value.(string))},
+		{caseName: "test integer", src: int64(1), shouldFail: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			var got Message
+			err := got.Scan(tc.src)
+			if (err != nil) != tc.shouldFail {
+				t.Fatalf("expect error %v, got %v", tc.shouldFail, err)
+			}
+			if !tc.shouldFail && (got.Variant != "Murray" || string(got.Codes) != string(m.Codes)) {
+				t.Errorf("expect %v, got %v", m, got)
+			}
+		})
+	}
+
+	got := *m
+	if err := got.Scan(nil); err != nil || got.Variant != "" || got.Codes != nil {
+		t.Errorf("expect an empty message, got %v, %v", got, err)
+	}
+	if value, err := got.Value(); err != nil || value != nil {
+		t.Errorf("expect NULL, got %v, %v", value, err)
+	}
+}
+
+func TestMessageCustomVariant(t *testing.T) {
+	def, _ := Definition(NewITA2(false))
+	def.Name = "ITA2 test"
+	c, err := NewVariant(*def, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ := NewMessage(c, "OK")
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"variant":"ITA2 test","text":"OK"}` {
+		t.Errorf("expect the custom variant, got %s, %v", data, err)
+	}
+}
+
+func TestMessageNationalVariants(t *testing.T) {
+	n, err := NewITA2National(NationalProfile{Name: "Message N", Assignments: map[byte]rune{20: 'Ñ'}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewITA2National(NationalProfile{Name: "Message C", Assignments: map[byte]rune{20: 'Ç'}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the variant of a message is its name, so another profile can't take the name
+	if _, err := NewITA2National(NationalProfile{Name: "Message N", Assignments: map[byte]rune{20: 'Ç'}}, false); err == nil {
+		t.Fatalf("expect an error for a second profile named Message N")
+	}
+
+	for _, tc := range []struct {
+		codec Codec
+		text  string
+	}{{n, "Ñ"}, {c, "Ç"}} {
+		m, _ := NewMessage(tc.codec, tc.text)
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Message
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if text, err := got.Text(); err != nil || text != tc.text {
+			t.Errorf("expect %q, got %s decoded to %q, %v", tc.text, data, text, err)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+/*
+ * Morse code and the bridge between Morse and Baudot circuits.
+ * Morse text form writes dots and dashes, characters are separated by a space and words by " / ",
+ * prosigns are written in angle brackets, e.g. "<SK>".
+ * The timing form follows the PARIS standard, 1 dot unit lasts 1200/wpm milliseconds,
+ * Farnsworth spacing stretches the gaps between characters and words to lower the overall speed.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var charmapMorse = map[rune]string{
+	'A':  ".-",
+	'B':  "-...",
+	'C':  "-.-.",
+	'D':  "-..",
+	'E':  ".",
+	'F':  "..-.",
+	'G':  "--.",
+	'H':  "....",
+	'I':  "..",
+	'J':  ".---",
+	'K':  "-.-",
+	'L':  ".-..",
+	'M':  "--",
+	'N':  "-.",
+	'O':  "---",
+	'P':  ".--.",
+	'Q':  "--.-",
+	'R':  ".-.",
+	'S':  "...",
+	'T':  "-",
+	'U':  "..-",
+	'V':  "...-",
+	'W':  ".--",
+	'X':  "-..-",
+	'Y':  "-.--",
+	'Z':  "--..",
+	'0':  "-----",
+	'1':  ".----",
+	'2':  "..---",
+	'3':  "...--",
+	'4':  "....-",
+	'5':  ".....",
+	'6':  "-....",
+	'7':  "--...",
+	'8':  "---..",
+	'9':  "----.",
+	'.':  ".-.-.-",
+	',':  "--..--",
+	'?':  "..--..",
+	'\'': ".----.",
+	'!':  "-.-.--",
+	'/':  "-..-.",
+	'(':  "-.--.",
+	')':  "-.--.-",
+	'&':  ".-...",
+	':':  "---...",
+	';':  "-.-.-.",
+	'=':  "-...-",
+	'+':  ".-.-.",
+	'-':  "-....-",
+	'"':  ".-..-.",
+	'$':  "...-..-",
+	'@':  ".--.-.",
+}
+
+var charsetMorse = func() map[string]rune {
+	m := make(map[string]rune, len(charmapMorse))
+	for char, elements := range charmapMorse {
+		m[elements] = char
+	}
+
+	return m
+}()
+
+type prosign struct {
+	elements string
+	// equivalent Baudot text, used when the target variant has no character sharing the prosign's elements
+	baudot string
+}
+
+var prosignsMorse = map[string]prosign{
+	// end of message
+	"AR": {".-.-.", "+"},
+	// break, new paragraph
+	"BT": {"-...-", "\r\n"},
+	// go ahead, named station only
+	"KN": {"-.--.", "("},
+	// end of work
+	"SK": {"...-.-", "\r\nNNNN"},
+	// error
+	"HH": {"........", "XXXXX"},
+	// understood
+	"SN": {"...-.", "\r\n"},
+}
+
+// prosignsBaudot lists the prosigns whose Baudot equivalent is more than a line break or a single character,
+// longest equivalent first, ToMorse turns these equivalents back into the prosigns
+var prosignsBaudot = func() []string {
+	var names []string
+	for name, p := range prosignsMorse {
+		if len(strings.TrimSpace(p.baudot)) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(prosignsMorse[names[i]].baudot) > len(prosignsMorse[names[j]].baudot)
+	})
+
+	return names
+}()
+
+// MorseElement is a key down(On) or key up period of the timing form
+type MorseElement struct {
+	On       bool
+	Duration time.Duration
+}
+
+type morse struct {
+	wpm           int
+	farnsworthWPM int
+}
+
+// NewMorse creates a Morse codec sending characters at wpm words per minute,
+// a farnsworthWPM lower than wpm stretches the spacing, 0 disables Farnsworth spacing
+func NewMorse(wpm int, farnsworthWPM int) *morse {
+	if wpm <= 0 {
+		wpm = 20
+	}
+	if farnsworthWPM <= 0 || farnsworthWPM > wpm {
+		farnsworthWPM = wpm
+	}
+
+	return &morse{
+		wpm:           wpm,
+		farnsworthWPM: farnsworthWPM,
+	}
+}
+
+// Encode string into Morse text form
+func (m *morse) Encode(msg string) (string, error) {
+	var words []string
+	for _, word := range splitWordsMorse(msg) {
+		var letters []string
+		for _, token := range tokenizeMorse(word) {
+			elements, ok := elementsOfMorse(token)
+			if !ok {
+				return "", fmt.Errorf("Invalid Char: %s", token)
+			}
+			letters = append(letters, elements)
+		}
+		words = append(words, strings.Join(letters, " "))
+	}
+
+	return strings.Join(words, " / "), nil
+}
+
+// Decode Morse text form to string, elements matching no character but a prosign are decoded as "<XX>"
+func (m *morse) Decode(text string) (string, error) {
+	var words []string
+	for _, word := range strings.Split(text, "/") {
+		var sb strings.Builder
+		for _, elements := range strings.Fields(word) {
+			token, ok := tokenOfMorse(elements)
+			if !ok {
+				return "", fmt.Errorf("Invalid Morse: %s", elements)
+			}
+			sb.WriteString(token)
+		}
+		if sb.Len() > 0 {
+			words = append(words, sb.String())
+		}
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// Timing encodes string into key down/up periods
+func (m *morse) Timing(msg string) ([]MorseElement, error) {
+	text, err := m.Encode(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	unit, charGap, wordGap := m.durations()
+	var elements []MorseElement
+	appendGap := func(d time.Duration) {
+		if len(elements) > 0 {
+			elements = append(elements, MorseElement{On: false, Duration: d})
+		}
+	}
+
+	for i, word := range strings.Split(text, " / ") {
+		if i > 0 {
+			appendGap(wordGap)
+		}
+		for j, letter := range strings.Fields(word) {
+			if j > 0 {
+				appendGap(charGap)
+			}
+			for k, element := range letter {
+				if k > 0 {
+					appendGap(unit)
+				}
+				d := unit
+				if element == '-' {
+					d = 3 * unit
+				}
+				elements = append(elements, MorseElement{On: true, Duration: d})
+			}
+		}
+	}
+
+	return elements, nil
+}
+
+// DecodeTiming decodes key down/up periods to string, periods are classified against the configured speed
+func (m *morse) DecodeTiming(elements []MorseElement) (string, error) {
+	unit, charGap, wordGap := m.durations()
+	dashThreshold := 2 * unit
+	charThreshold := (unit + charGap) / 2
+	wordThreshold := (charGap + wordGap) / 2
+
+	var sb strings.Builder
+	for _, element := range elements {
+		if element.On {
+			if element.Duration < dashThreshold {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteByte('-')
+			}
+			continue
+		}
+
+		if element.Duration >= wordThreshold {
+			sb.WriteString(" / ")
+		} else if element.Duration >= charThreshold {
+			sb.WriteByte(' ')
+		}
+	}
+
+	return m.Decode(sb.String())
+}
+
+// Duration returns the time taken to send the string
+func (m *morse) Duration(msg string) (time.Duration, error) {
+	elements, err := m.Timing(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	var total time.Duration
+	for _, element := range elements {
+		total += element.Duration
+	}
+
+	return total, nil
+}
+
+// durations returns the dot unit and the gaps between characters and words
+func (m *morse) durations() (time.Duration, time.Duration, time.Duration) {
+	unit := 1200 * time.Millisecond / time.Duration(m.wpm)
+	if m.farnsworthWPM >= m.wpm {
+		return unit, 3 * unit, 7 * unit
+	}
+
+	// ARRL Farnsworth timing, the total delay of a PARIS word is spread over 19 units of gaps
+	c, s := float64(m.wpm), float64(m.farnsworthWPM)
+	delay := time.Duration((60*c - 37.2*s) / (s * c) * float64(time.Second))
+
+	return unit, 3 * delay / 19, 7 * delay / 19
+}
+
+func splitWordsMorse(msg string) []string {
+	return strings.FieldsFunc(msg, func(r rune) bool {
+		return r == ' ' || r == '\r' || r == '\n' || r == '\t'
+	})
+}
+
+// tokenizeMorse splits a word into characters and "<XX>" prosigns
+func tokenizeMorse(word string) []string {
+	var tokens []string
+	runes := []rune(word)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '<' {
+			if end := strings.IndexRune(string(runes[i:]), '>'); end > 0 {
+				token := string(runes[i:])[:end+1]
+				tokens = append(tokens, token)
+				i += len([]rune(token)) - 1
+				continue
+			}
+		}
+		tokens = append(tokens, string(runes[i]))
+	}
+
+	return tokens
+}
+
+func elementsOfMorse(token string) (string, bool) {
+	if strings.HasPrefix(token, "<") {
+		p, ok := prosignsMorse[strings.ToUpper(strings.Trim(token, "<>"))]
+		return p.elements, ok
+	}
+
+	elements, ok := charmapMorse[unicode.ToUpper([]rune(token)[0])]
+
+	return elements, ok
+}
+
+func tokenOfMorse(elements string) (string, bool) {
+	if char, ok := charsetMorse[elements]; ok {
+		return string(char), true
+	}
+	for name, p := range prosignsMorse {
+		if p.elements == elements {
+			return "<" + name + ">", true
+		}
+	}
+
+	return "", false
+}
+
+type morseBridge struct {
+	codec Codec
+	morse *morse
+}
+
+// NewMorseBridge connects a Baudot codec(e.g. NewITA2 or NewUSTTY) with a Morse codec,
+// the codec should not ignore errors, otherwise characters missing from the variant can't be reported
+func NewMorseBridge(codec Codec, m *morse) *morseBridge {
+	return &morseBridge{
+		codec: codec,
+		morse: m,
+	}
+}
+
+// ToMorse converts Baudot codes into Morse text form, the Baudot equivalents of prosigns like "\r\nNNNN" are sent as the prosigns,
+// returns the characters which have no Morse counterpart
+func (b *morseBridge) ToMorse(codes []byte) (string, []rune, error) {
+	text, err := b.codec.Decode(codes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		sb      strings.Builder
+		missing []rune
+	)
+next:
+	for len(text) > 0 {
+		for _, name := range prosignsBaudot {
+			if equivalent := prosignsMorse[name].baudot; strings.HasPrefix(text, equivalent) {
+				sb.WriteString(" <" + name + "> ")
+				text = text[len(equivalent):]
+				continue next
+			}
+		}
+
+		char, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		switch {
+		case char == '\r':
+		case char == '\n' || char == ' ':
+			sb.WriteRune(' ')
+		default:
+			if _, ok := charmapMorse[unicode.ToUpper(char)]; ok {
+				sb.WriteRune(char)
+			} else {
+				missing = append(missing, char)
+			}
+		}
+	}
+
+	morseText, err := b.morse.Encode(sb.String())
+
+	return morseText, missing, err
+}
+
+// FromMorse converts Morse text form into Baudot codes, prosigns are replaced with their Baudot equivalents,
+// returns the characters and prosigns which have no counterpart in the Baudot variant
+func (b *morseBridge) FromMorse(morseText string) ([]byte, []string, error) {
+	var (
+		sb      strings.Builder
+		missing []string
+	)
+	for i, word := range strings.Split(morseText, "/") {
+		if i > 0 {
+			sb.WriteRune(' ')
+		}
+		for _, elements := range strings.Fields(word) {
+			token, ok := tokenOfMorse(elements)
+			if !ok {
+				missing = append(missing, elements)
+				continue
+			}
+
+			if !strings.HasPrefix(token, "<") && b.canEncode(token) {
+				sb.WriteString(token)
+				continue
+			}
+
+			// no such character in the variant, try the prosign sharing the same elements
+			if equivalent, ok := b.prosignEquivalent(elements); ok {
+				sb.WriteString(equivalent)
+				continue
+			}
+			missing = append(missing, token)
+		}
+	}
+
+	codes, err := b.codec.Encode(strings.Trim(sb.String(), " "))
+
+	return codes, missing, err
+}
+
+func (b *morseBridge) prosignEquivalent(elements string) (string, bool) {
+	for _, p := range prosignsMorse {
+		if p.elements == elements && b.canEncode(p.baudot) {
+			return p.baudot, true
+		}
+	}
+
+	return "", false
+}
+
+func (b *morseBridge) canEncode(text string) bool {
+	_, err := b.codec.Encode(text)
+
+	return err == nil
+}
@@ -0,0 +1,206 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMorseEncode(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		msg        string
+		expect     string
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test words",
+			msg:        "CQ de K1",
+			expect:     "-.-. --.- / -.. . / -.- .----",
+			failedText: "expect '-.-. --.- / -.. . / -.- .----', got %v",
+		},
+		{
+			caseName:   "test prosign",
+			msg:        "73 <SK>",
+			expect:     "--... ...-- / ...-.-",
+			failedText: "expect '--... ...-- / ...-.-', got %v",
+		},
+		{
+			caseName:   "test invalid char",
+			msg:        "£5",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	m := NewMorse(20, 0)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			text, err := m.Encode(tc.msg)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", text, err))
+				}
+			} else if tc.shouldFail || tc.expect != text {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", text, err))
+			}
+		})
+	}
+}
+
+func TestMorseDecode(t *testing.T) {
+	m := NewMorse(20, 0)
+	str, err := m.Decode("-.-. --.- / ...-.- / -...-")
+	if err != nil || str != "CQ <SK> =" {
+		t.Errorf("expect 'CQ <SK> =', got %v, %v", str, err)
+	}
+
+	if _, err := m.Decode(".-.-.-.-"); err == nil {
+		t.Errorf("expect an error for unknown elements")
+	}
+}
+
+func TestMorseTiming(t *testing.T) {
+	tt := []struct {
+		caseName      string
+		wpm           int
+		farnsworthWPM int
+		expect        time.Duration
+	}{
+		{
+			caseName: "test PARIS at 20 wpm",
+			wpm:      20,
+			// 43 units of PARIS without the trailing word gap
+			expect: 43 * 60 * time.Millisecond,
+		},
+		{
+			caseName:      "test PARIS at 18 wpm with 5 wpm Farnsworth spacing",
+			wpm:           18,
+			farnsworthWPM: 5,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			m := NewMorse(tc.wpm, tc.farnsworthWPM)
+			elements, err := m.Timing("PARIS")
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			d, _ := m.Duration("PARIS")
+			if tc.expect != 0 && d != tc.expect {
+				t.Errorf("expect %v, got %v", tc.expect, d)
+			}
+			if tc.farnsworthWPM != 0 {
+				if plain, _ := NewMorse(tc.wpm, 0).Duration("PARIS"); d <= plain {
+					t.Errorf("expect Farnsworth spacing to be slower than %v, got %v", plain, d)
+				}
+			}
+
+			str, err := m.DecodeTiming(elements)
+			if err != nil || str != "PARIS" {
+				t.Errorf("expect 'PARIS', got %v, %v", str, err)
+			}
+		})
+	}
+
+	m := NewMorse(18, 5)
+	elements, _ := m.Timing("CQ CQ")
+	if str, err := m.DecodeTiming(elements); err != nil || str != "CQ CQ" {
+		t.Errorf("expect 'CQ CQ', got %v, %v", str, err)
+	}
+}
+
+func TestMorseBridge(t *testing.T) {
+	tt := []struct {
+		caseName      string
+		codec         Codec
+		morse         string
+		expectText    string
+		expectMissing int
+		failedText    string
+	}{
+		{
+			caseName:   "test ITA2 keeps equal sign",
+			codec:      NewITA2(false),
+			morse:      "--.- ... .-.. / -...- / ...-.-",
+			expectText: "QSL = \r\nNNNN",
+			failedText: "expect 'QSL = \\r\\nNNNN', got %v",
+		},
+		{
+			caseName:   "test US TTY replaces break prosign",
+			codec:      NewUSTTY(false),
+			morse:      "--.- ... .-.. / -...-",
+			expectText: "QSL \r\n",
+			failedText: "expect 'QSL \\r\\n', got %v",
+		},
+		{
+			caseName:      "test US TTY reports missing plus sign",
+			codec:         NewUSTTY(false),
+			morse:         "..--- / .-.-.-.-",
+			expectText:    "2",
+			expectMissing: 1,
+			failedText:    "expect '2' with one missing token, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			b := NewMorseBridge(tc.codec, NewMorse(20, 0))
+			codes, missing, err := b.FromMorse(tc.morse)
+			if err != nil {
+				t.Fatalf(tc.failedText, err)
+			}
+			str, _ := tc.codec.Decode(codes)
+			if str != tc.expectText || len(missing) != tc.expectMissing {
+				t.Errorf(tc.failedText, fmt.Sprintf("%q, %v", str, missing))
+			}
+		})
+	}
+
+	b := NewMorseBridge(NewITA2(false), NewMorse(20, 0))
+	codes, _ := NewITA2(false).Encode("5£ OK\r\n")
+	text, missing, err := b.ToMorse(codes)
+	if err != nil || text != "..... / --- -.-" || len(missing) != 1 || missing[0] != '£' {
+		t.Errorf("expect '..... / --- -.-' with '£' missing, got %v, %v, %v", text, missing, err)
+	}
+}
+
+func TestMorseBridgeRoundTrip(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		codec      Codec
+		morse      string
+		failedText string
+	}{
+		{
+			caseName:   "test ITA2 prosigns",
+			codec:      NewITA2(false),
+			morse:      "--.- ... .-.. / .-.-. / ........ / ...-.-",
+			failedText: "expect '--.- ... .-.. / .-.-. / ........ / ...-.-', got %v",
+		},
+		{
+			caseName:   "test US TTY prosigns",
+			codec:      NewUSTTY(false),
+			morse:      "-.-. --.- / -.--. / ...-.-",
+			failedText: "expect '-.-. --.- / -.--. / ...-.-', got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			b := NewMorseBridge(tc.codec, NewMorse(20, 0))
+			codes, missing, err := b.FromMorse(tc.morse)
+			if err != nil || len(missing) != 0 {
+				t.Fatalf(tc.failedText, fmt.Sprintf("%v, %v", missing, err))
+			}
+
+			text, missingChars, err := b.ToMorse(codes)
+			if err != nil || text != tc.morse || len(missingChars) != 0 {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v, %v", text, missingChars, err))
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Murray code is the 1901 code of Donald Murray's printing telegraph, the predecessor of ITA2.
+ * The letters mostly sit where ITA2 later put them, but the carriage controls differ:
+ * COL(code 2) returns the carriage to the first column and LINE(code 8) feeds the paper, the opposite of ITA2's LF and CR.
+ * The figures register carries the fraction keys of the period instead of ITA2's bell and WRU.
+ */
+
+package baudot
+
+// Encode string into byte array represent the sequence of Murray code
+func (c *murray) Encode(msg string) ([]byte, error) {
+	return encode(msg, c.ignErr, versionMurray)
+}
+
+// Decode Murray code to string, COL is decoded to '\r' and LINE to '\n'
+func (c *murray) Decode(codes []byte) (string, error) {
+	return decode(codes, c.ignErr, versionMurray)
+}
+
+// EncodeChar encodes a character into Murray code
+func (c *murray) EncodeChar(char rune, currentCharset Charset) (byte, bool, error) {
+	code, shiftedCharset, err := encodeChar(char, currentCharset, versionMurray)
+
+	return code, shiftedCharset != currentCharset, err
+}
+
+// DecodeChar decodes a Murray code to rune
+func (c *murray) DecodeChar(code byte, currentCharset Charset) (rune, bool, error) {
+	char, shiftedCharset, err := decodeChar(code, currentCharset, versionMurray)
+
+	return char, currentCharset != shiftedCharset, err
+}
+
+func (c *murray) ver() version {
+	return versionMurray
+}
+
+// Repertoire returns the characters of a register in code order
+func (c *murray) Repertoire(charset Charset) []rune {
+	return repertoire(versionMurray, charset)
+}
+
+// CanEncode reports whether every character of msg is in Murray code
+func (c *murray) CanEncode(msg string) bool {
+	return len(c.Unsupported(msg)) == 0
+}
+
+// Unsupported returns the characters of msg which are not in Murray code
+func (c *murray) Unsupported(msg string) []UnsupportedChar {
+	return unsupported(msg, versionMurray, identity)
+}
+
+// EncodedLen returns the number of codes Encode returns for msg, shift codes included
+func (c *murray) EncodedLen(msg string) (int, error) {
+	return encodedLen(msg, c.ignErr, versionMurray)
+}
@@ -0,0 +1,76 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMurrayDecode(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		codes      []byte
+		ignErr     bool
+		expect     string
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test COL and LINE",
+			codes:      []byte{0, 31, 20, 6, 2, 8, 19},
+			expect:     "HI\r\nW",
+			failedText: "expect 'HI\\r\\nW', got %v",
+		},
+		{
+			caseName:   "test fractions",
+			codes:      []byte{0, 31, 27, 7, 13, 4, 23, 26},
+			expect:     "7½ 1¼",
+			failedText: "expect '7½ 1¼', got %v",
+		},
+		{
+			caseName:   "test invalid code",
+			codes:      []byte{0, 31, 20, 40},
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+		{
+			caseName:   "test invalid code, ignore error",
+			codes:      []byte{0, 31, 20, 40},
+			ignErr:     true,
+			expect:     "H",
+			failedText: "expect 'H', got %v",
+		},
+	}
+
+	c := NewMurray(false)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			c.ignErr = tc.ignErr
+			str, err := c.Decode(tc.codes)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%q, %v", str, err))
+				}
+			} else if tc.shouldFail || tc.expect != str {
+				t.Errorf(tc.failedText, fmt.Sprintf("%q, %v", str, err))
+			}
+		})
+	}
+}
+
+func TestMurrayEncode(t *testing.T) {
+	c := NewMurray(false)
+	codes, err := c.Encode("3¾ IN\r\n")
+	expect := []byte{0, 31, 27, 1, 20, 4, 31, 6, 12, 2, 8}
+	if err != nil || string(codes) != string(expect) {
+		t.Errorf("expect %v, got %v, %v", expect, codes, err)
+	}
+
+	if _, err := c.Encode("\u0007"); err == nil {
+		t.Errorf("expect an error for bell, Murray code has none")
+	}
+
+	// the same tape read as ITA2 swaps the carriage controls
+	if str, _ := NewITA2(false).Decode([]byte{0, 31, 20, 6, 2, 8}); str != "HI\n\r" {
+		t.Errorf("expect ITA2 to decode 'HI\\n\\r', got %q", str)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * National versions of ITA2. CCITT left some figures positions for national use,
+ * the international alphabet has ' on S, WRU on D, bell on J, ! on F, £ on H and & on G there,
+ * networks replaced them with their own characters, e.g. $ and # in the US, Ä, Ö, Ü in Germany.
+ * A national codec is ITA2 with the figures register changed on these positions only.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NationalPositions are the figures positions which may be assigned by a national profile
+var NationalPositions = []byte{5, 9, 11, 13, 20, 26}
+
+// NationalProfile assigns characters to national-use figures positions, positions not assigned keep the international characters
+type NationalProfile struct {
+	Name        string
+	Assignments map[byte]rune
+}
+
+var (
+	NationalInternational = NationalProfile{Name: "International"}
+	NationalUS            = NationalProfile{Name: "US", Assignments: map[byte]rune{9: '$', 20: '#'}}
+	NationalGerman        = NationalProfile{Name: "German", Assignments: map[byte]rune{13: 'Ä', 20: 'Ü', 26: 'Ö'}}
+	NationalSwedish       = NationalProfile{Name: "Swedish", Assignments: map[byte]rune{13: 'Å', 20: 'Ö', 26: 'Ä'}}
+	NationalDanish        = NationalProfile{Name: "Danish-Norwegian", Assignments: map[byte]rune{13: 'Æ', 20: 'Å', 26: 'Ø'}}
+)
+
+// NationalProfiles returns the built-in national profiles
+func NationalProfiles() []NationalProfile {
+	return []NationalProfile{NationalInternational, NationalUS, NationalGerman, NationalSwedish, NationalDanish}
+}
+
+// NewITA2National creates an ITA2 codec with the figures register of profile, named "ITA2 " and the name of the profile.
+// Profiles with the same name must have the same assignments.
+func NewITA2National(profile NationalProfile, ignoreError bool) (*ita2, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	if len(profile.Assignments) == 0 {
+		return NewITA2(ignoreError), nil
+	}
+
+	figures := map[byte]rune{}
+	for code, char := range figuresITA2 {
+		figures[code] = char
+	}
+	for code, char := range profile.Assignments {
+		figures[code] = char
+	}
+	t := twoRegisters("ITA2 "+profile.Name, lettersITA2, figures, nil, LS, FS, []byte{NULL})
+	t.charmap = charmapOf(t.registers)
+
+	ver, err := registerTables(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ita2{
+		ignErr:  ignoreError,
+		version: ver,
+	}, nil
+}
+
+// Validate checks the profile only assigns national-use positions, with characters which are not on any other position
+func (p NationalProfile) Validate() error {
+	assigned := map[rune]byte{}
+	for _, code := range p.codes() {
+		char := p.Assignments[code]
+		if !isNationalPosition(code) {
+			return fmt.Errorf("Invalid national position: %d", code)
+		}
+		if char == '\u0000' {
+			return fmt.Errorf("Invalid Char: %c", char)
+		}
+		if other, ok := assigned[char]; ok {
+			return fmt.Errorf("Duplicate Char: %c on %d and %d", char, other, code)
+		}
+		assigned[char] = code
+
+		for other, c := range lettersITA2 {
+			if c == char {
+				return fmt.Errorf("Duplicate Char: %c on %d and letters %d", char, code, other)
+			}
+		}
+		for other, c := range figuresITA2 {
+			if c == char && other != code {
+				if _, ok := p.Assignments[other]; !ok {
+					return fmt.Errorf("Duplicate Char: %c on %d and figures %d", char, code, other)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// codes returns the assigned positions in order
+func (p NationalProfile) codes() []byte {
+	codes := make([]byte, 0, len(p.Assignments))
+	for code := range p.Assignments {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	return codes
+}
+
+func isNationalPosition(code byte) bool {
+	for _, position := range NationalPositions {
+		if position == code {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNationalEncode(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		profile    NationalProfile
+		msg        string
+		expect     []byte
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test international",
+			profile:    NationalInternational,
+			msg:        "£5",
+			expect:     []byte{0, 31, 27, 20, 16},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 31, 27, 20, 16}),
+		},
+		{
+			caseName:   "test US",
+			profile:    NationalUS,
+			msg:        "$5 #1",
+			expect:     []byte{0, 31, 27, 9, 16, 4, 20, 23},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 31, 27, 9, 16, 4, 20, 23}),
+		},
+		{
+			caseName:   "test German",
+			profile:    NationalGerman,
+			msg:        "MÜNCHEN",
+			expect:     []byte{0, 31, 28, 27, 20, 31, 12, 14, 20, 1, 12},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 31, 28, 27, 20, 31, 12, 14, 20, 1, 12}),
+		},
+		{
+			caseName:   "test replaced character",
+			profile:    NationalSwedish,
+			msg:        "£",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			c, err := NewITA2National(tc.profile, false)
+			if err != nil {
+				t.Fatalf(tc.failedText, err)
+			}
+			codes, err := c.Encode(tc.msg)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+				}
+			} else if tc.shouldFail || string(codes) != string(tc.expect) {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+			}
+		})
+	}
+}
+
+func TestNationalDecode(t *testing.T) {
+	codes := []byte{0, 31, 27, 13, 26, 20, 4, 5, 9}
+	tt := []struct {
+		caseName string
+		profile  NationalProfile
+		expect   string
+	}{
+		{caseName: "test international", profile: NationalInternational, expect: "!&£ '\u0005"},
+		{caseName: "test German", profile: NationalGerman, expect: "ÄÖÜ '\u0005"},
+		{caseName: "test Swedish", profile: NationalSwedish, expect: "ÅÄÖ '\u0005"},
+		{caseName: "test Danish-Norwegian", profile: NationalDanish, expect: "ÆØÅ '\u0005"},
+		{caseName: "test US", profile: NationalUS, expect: "!&# '$"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			c, err := NewITA2National(tc.profile, false)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			str, err := c.Decode(codes)
+			if err != nil || str != tc.expect {
+				t.Errorf("expect %q, got %q, %v", tc.expect, str, err)
+			}
+		})
+	}
+}
+
+func TestNationalValidate(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		profile    NationalProfile
+		shouldFail bool
+	}{
+		{caseName: "test built-in profiles", profile: NationalGerman},
+		{caseName: "test swapped positions", profile: NationalProfile{Assignments: map[byte]rune{13: '&', 26: '!'}}},
+		{caseName: "test reserved position", profile: NationalProfile{Assignments: map[byte]rune{1: 'Ä'}}, shouldFail: true},
+		{caseName: "test same character twice", profile: NationalProfile{Assignments: map[byte]rune{13: 'Ä', 26: 'Ä'}}, shouldFail: true},
+		{caseName: "test collision with figures", profile: NationalProfile{Assignments: map[byte]rune{20: '1'}}, shouldFail: true},
+		{caseName: "test collision with letters", profile: NationalProfile{Assignments: map[byte]rune{20: 'A'}}, shouldFail: true},
+		{caseName: "test null", profile: NationalProfile{Assignments: map[byte]rune{20: '\u0000'}}, shouldFail: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			_, err := NewITA2National(tc.profile, false)
+			if (err != nil) != tc.shouldFail {
+				t.Errorf("expect failure %v, got %v", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
+func TestNationalSharedTables(t *testing.T) {
+	a, _ := NewITA2National(NationalProfile{Name: "Shared", Assignments: map[byte]rune{20: 'Ñ'}}, false)
+	b, _ := NewITA2National(NationalProfile{Name: "Shared", Assignments: map[byte]rune{20: 'Ñ'}}, false)
+	if a.ver() != b.ver() {
+		t.Errorf("expect the same version, got %v and %v", a.ver(), b.ver())
+	}
+	// the name identifies the variant
+	if _, err := NewITA2National(NationalProfile{Name: "Shared", Assignments: map[byte]rune{20: 'Ç'}}, false); err == nil {
+		t.Errorf("expect an error for another profile with the same name")
+	}
+	if a.ver() == versionITA2 {
+		t.Errorf("expect a runtime version, got %v", a.ver())
+	}
+
+	p, err := NewPrinter(a, CarriageWidth69, MarginJam)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	p.Print([]byte{27, 20})
+	if p.String() != "Ñ" {
+		t.Errorf("expect 'Ñ', got %q", p.String())
+	}
+}
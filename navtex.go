@@ -0,0 +1,155 @@
+/*
+ * NAVTEX messages are framed as "ZCZC B1B2B3B4" ... "NNNN" in the decoded SITOR-B text,
+ * B1 is the transmitter identity, B2 the subject indicator and B3B4 the serial number.
+ */
+
+package baudot
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	navtexStart = "ZCZC"
+	navtexEnd   = "NNNN"
+	// messages with a character error rate above this are not accepted
+	maxCERNAVTEX = 0.04
+	// text buffered for an unfinished frame, older text past it is dropped
+	maxNAVTEXBuffer = 16 << 10
+)
+
+// NAVTEXMessage is a message record extracted from decoded NAVTEX text
+type NAVTEXMessage struct {
+	// B1, transmitter identity A-Z
+	Transmitter byte
+	// B2, subject indicator A-Z
+	Subject byte
+	// B3B4, serial number 00-99, messages numbered 00 are always accepted
+	Serial int
+	Body   string
+	// number of erased characters in the body
+	Errors int
+	// Rejected is true if the character error rate exceeds the standard's limit(4%)
+	Rejected bool
+}
+
+// ID returns the B1B2B3B4 message identity
+func (m NAVTEXMessage) ID() string {
+	return string([]byte{m.Transmitter, m.Subject}) + strconv.Itoa(m.Serial/10) + strconv.Itoa(m.Serial%10)
+}
+
+// CER returns the character error rate of the body
+func (m NAVTEXMessage) CER() float64 {
+	if len(m.Body) == 0 {
+		return 0
+	}
+
+	return float64(m.Errors) / float64(len([]rune(m.Body)))
+}
+
+type navtexParser struct {
+	buf      strings.Builder
+	accepted map[string]bool
+}
+
+func NewNAVTEXParser() *navtexParser {
+	return &navtexParser{
+		accepted: map[string]bool{},
+	}
+}
+
+// Feed decoded text into the parser, returns the messages completed by this chunk.
+// Repeats of an already accepted message are dropped, a rejected message may be replaced by a later repeat.
+// A frame whose "NNNN" is lost is discarded when the next "ZCZC" arrives.
+func (p *navtexParser) Feed(text string) []NAVTEXMessage {
+	p.buf.WriteString(text)
+	data := p.buf.String()
+
+	var messages []NAVTEXMessage
+	for {
+		start := strings.Index(data, navtexStart)
+		if start < 0 {
+			// keep a possible partial start of frame
+			if len(data) >= len(navtexStart) {
+				data = data[len(data)-len(navtexStart)+1:]
+			}
+			break
+		}
+
+		end := strings.Index(data[start:], navtexEnd)
+		next := strings.Index(data[start+len(navtexStart):], navtexStart)
+		if next >= 0 && (end < 0 || next+len(navtexStart) < end) {
+			// the end of this frame was lost, restart at the next one
+			data = data[start+len(navtexStart)+next:]
+			continue
+		}
+		if end < 0 {
+			data = data[start:]
+			break
+		}
+
+		frame := data[start+len(navtexStart) : start+end]
+		data = data[start+end+len(navtexEnd):]
+
+		msg, ok := parseNAVTEXFrame(frame)
+		if !ok {
+			continue
+		}
+
+		id := msg.ID()
+		if msg.Serial != 0 && p.accepted[id] {
+			continue
+		}
+		if !msg.Rejected {
+			p.accepted[id] = true
+		}
+		messages = append(messages, msg)
+	}
+
+	if len(data) > maxNAVTEXBuffer {
+		data = data[len(data)-maxNAVTEXBuffer:]
+	}
+	p.buf.Reset()
+	p.buf.WriteString(data)
+
+	return messages
+}
+
+// ParseNAVTEX extracts every message from a complete decoded text
+func ParseNAVTEX(text string) []NAVTEXMessage {
+	return NewNAVTEXParser().Feed(text)
+}
+
+// parseNAVTEXFrame parses the text between "ZCZC" and "NNNN"
+func parseNAVTEXFrame(frame string) (NAVTEXMessage, bool) {
+	frame = strings.TrimLeft(frame, " ")
+	if len(frame) < 4 {
+		return NAVTEXMessage{}, false
+	}
+
+	header := frame[:4]
+	if !isNAVTEXLetter(header[0]) || !isNAVTEXLetter(header[1]) {
+		return NAVTEXMessage{}, false
+	}
+	serial, err := strconv.Atoi(header[2:])
+	if err != nil || header[2] < '0' || header[2] > '9' || header[3] < '0' || header[3] > '9' {
+		return NAVTEXMessage{}, false
+	}
+
+	body := strings.Trim(frame[4:], " \r\n")
+	msg := NAVTEXMessage{
+		Transmitter: header[0],
+		Subject:     header[1],
+		Serial:      serial,
+		Body:        body,
+		Errors:      strings.Count(body, string(ERASURE_CHAR)),
+	}
+	msg.Rejected = msg.CER() > maxCERNAVTEX
+
+	return msg, true
+}
+
+func isNAVTEXLetter(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,128 @@
+package baudot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNAVTEXParse(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		text       string
+		expect     []string
+		rejected   []bool
+		failedText string
+	}{
+		{
+			caseName:   "test single message",
+			text:       "ZCZC FA12\r\nGALE WARNING\r\nNNNN",
+			expect:     []string{"FA12"},
+			rejected:   []bool{false},
+			failedText: "expect one message FA12, got %v",
+		},
+		{
+			caseName:   "test noise around messages",
+			text:       "XXZCZC FA12\r\nGALE WARNING\r\nNNNN\r\nNOISE ZCZC GB03\r\nICE\r\nNNNN",
+			expect:     []string{"FA12", "GB03"},
+			rejected:   []bool{false, false},
+			failedText: "expect messages FA12 and GB03, got %v",
+		},
+		{
+			caseName:   "test repeated message",
+			text:       "ZCZC FA12\r\nGALE\r\nNNNN ZCZC FA12\r\nGALE\r\nNNNN",
+			expect:     []string{"FA12"},
+			rejected:   []bool{false},
+			failedText: "expect repeat to be dropped, got %v",
+		},
+		{
+			caseName:   "test repeated message numbered 00",
+			text:       "ZCZC FD00\r\nSAR\r\nNNNN ZCZC FD00\r\nSAR\r\nNNNN",
+			expect:     []string{"FD00", "FD00"},
+			rejected:   []bool{false, false},
+			failedText: "expect both messages, got %v",
+		},
+		{
+			caseName:   "test rejected message is replaced by repeat",
+			text:       "ZCZC FA12\r\nG*LE*\r\nNNNN ZCZC FA12\r\nGALE!\r\nNNNN ZCZC FA12\r\nGALE!\r\nNNNN",
+			expect:     []string{"FA12", "FA12"},
+			rejected:   []bool{true, false},
+			failedText: "expect rejected message and its repeat, got %v",
+		},
+		{
+			caseName:   "test lost end of message",
+			text:       "ZCZC FA12\r\nGALE WARN ZCZC GB03\r\nICE\r\nNNNN",
+			expect:     []string{"GB03"},
+			rejected:   []bool{false},
+			failedText: "expect only message GB03, got %v",
+		},
+		{
+			caseName:   "test mutilated header",
+			text:       "ZCZC F*12\r\nGALE\r\nNNNN",
+			expect:     nil,
+			failedText: "expect no message, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			messages := ParseNAVTEX(tc.text)
+			if len(messages) != len(tc.expect) {
+				t.Fatalf(tc.failedText, fmt.Sprintf("%+v", messages))
+			}
+			for i, msg := range messages {
+				if msg.ID() != tc.expect[i] || msg.Rejected != tc.rejected[i] {
+					t.Errorf(tc.failedText, fmt.Sprintf("%+v", messages))
+				}
+			}
+		})
+	}
+}
+
+func TestNAVTEXFeed(t *testing.T) {
+	p := NewNAVTEXParser()
+	text := "ZCZC GA01\r\nNAVIGATIONAL WARNING\r\nNNNN"
+
+	var messages []NAVTEXMessage
+	for _, chunk := range strings.SplitAfter(text, "Z") {
+		messages = append(messages, p.Feed(chunk)...)
+	}
+
+	if len(messages) != 1 || messages[0].Body != "NAVIGATIONAL WARNING" || messages[0].Transmitter != 'G' || messages[0].Serial != 1 {
+		t.Errorf("expect message GA01 assembled from chunks, got %+v", messages)
+	}
+}
+
+func TestNAVTEXFeedLimit(t *testing.T) {
+	p := NewNAVTEXParser()
+	if messages := p.Feed("ZCZC FA12\r\n"); len(messages) != 0 {
+		t.Fatalf("expect no message, got %+v", messages)
+	}
+
+	noise := strings.Repeat("RY", maxNAVTEXBuffer)
+	if messages := p.Feed(noise); len(messages) != 0 {
+		t.Fatalf("expect no message, got %+v", messages)
+	}
+	if p.buf.Len() > maxNAVTEXBuffer {
+		t.Errorf("expect at most %d buffered bytes, got %d", maxNAVTEXBuffer, p.buf.Len())
+	}
+
+	messages := p.Feed("NNNN ZCZC GB03\r\nICE\r\nNNNN")
+	if len(messages) != 1 || messages[0].ID() != "GB03" {
+		t.Errorf("expect message GB03 after the dropped frame, got %+v", messages)
+	}
+}
+
+func TestNAVTEXFromSITORB(t *testing.T) {
+	c := NewSITORB(false)
+	emission, err := c.Encode("ZCZC OA05\r\nTEST\r\nNNNN")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	str, _ := c.Decode(emission)
+	messages := ParseNAVTEX(str)
+	if len(messages) != 1 || messages[0].ID() != "OA05" || messages[0].Body != "TEST" {
+		t.Errorf("expect message OA05, got %+v", messages)
+	}
+}
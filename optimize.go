@@ -0,0 +1,273 @@
+/*
+ * Shift minimizing encoder. encode always starts in Letters and only shifts when a character is missing from the current register.
+ * EncodeOptimized looks at the whole message and picks, for every character, the register which minimizes the number of codes.
+ * With 2 registers staying in the current register is already the best choice in the middle of a message,
+ * the savings come from starting the sequence with the shift code of the register the message starts in,
+ * and with USOS from planning for the receiver falling back to Letters after a space.
+ */
+
+package baudot
+
+import "fmt"
+
+type OptimizeOptions struct {
+	// UnshiftOnSpace encodes for receivers returning to Letters after every space(USOS)
+	UnshiftOnSpace bool
+	// IgnoreError drops characters the variant doesn't have instead of returning an error
+	IgnoreError bool
+}
+
+// EncodeStats compares the naive encoder with EncodeOptimized for a message,
+// the naive encoder is Encode, or Encode adding the shift codes needed after each space with USOS
+type EncodeStats struct {
+	Chars           int
+	NaiveCodes      int
+	NaiveShifts     int
+	OptimizedCodes  int
+	OptimizedShifts int
+}
+
+// Saved returns the number of codes saved by the optimized encoder
+func (s EncodeStats) Saved() int {
+	return s.NaiveCodes - s.OptimizedCodes
+}
+
+// EncodeOptimized encodes a message with the fewest codes, the sequence starts with NULL and a shift code like Encode
+func EncodeOptimized(codec Codec, msg string, options OptimizeOptions) ([]byte, error) {
+	v, ok := codec.(versioned)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported codec: %T", codec)
+	}
+
+	codes, err := encodeOptimized(normalize(codec, msg), options, v.ver())
+	if n, ok := codec.(normalizing); ok && err != nil {
+		return nil, unnormalizedPosition(err, msg, v.ver(), n.normalize)
+	}
+
+	return codes, err
+}
+
+// DecodeUnshiftOnSpace decodes codes sent to a receiver which returns to Letters after every space
+func DecodeUnshiftOnSpace(codec Codec, codes []byte, ignoreError bool) (string, error) {
+	v, ok := codec.(versioned)
+	if !ok {
+		return "", fmt.Errorf("Unsupported codec: %T", codec)
+	}
+
+	return decodeUSOS(codes, ignoreError, v.ver(), true)
+}
+
+// CompareEncoding reports the code counts of the naive and the optimized encoders for a message
+func CompareEncoding(codec Codec, msg string, options OptimizeOptions) (EncodeStats, error) {
+	v, ok := codec.(versioned)
+	if !ok {
+		return EncodeStats{}, fmt.Errorf("Unsupported codec: %T", codec)
+	}
+
+	msg = normalize(codec, msg)
+	naive, err := encodeNaive(msg, options, v.ver())
+	if err != nil {
+		return EncodeStats{}, err
+	}
+	optimized, err := encodeOptimized(msg, options, v.ver())
+	if err != nil {
+		return EncodeStats{}, err
+	}
+
+	naiveChars, naiveShifts := countCodes(naive, v.ver())
+	_, optimizedShifts := countCodes(optimized, v.ver())
+
+	return EncodeStats{
+		Chars:           naiveChars,
+		NaiveCodes:      len(naive),
+		NaiveShifts:     naiveShifts,
+		OptimizedCodes:  len(optimized),
+		OptimizedShifts: optimizedShifts,
+	}, nil
+}
+
+// countCodes reads codes like a receiver, it returns the number of characters and the number of shift codes,
+// the codes the sequence starts with are not counted
+func countCodes(codes []byte, ver version) (int, int) {
+	t, err := lookupTables(ver)
+	if err != nil {
+		return 0, 0
+	}
+
+	chars, shifts := 0, -1
+	charset := Letters
+	for _, code := range codes[len(t.start):] {
+		if shiftedCharset, ok := t.shifts[charset][code]; ok {
+			charset = shiftedCharset
+			shifts++
+		} else {
+			chars++
+		}
+	}
+	if shifts < 0 {
+		shifts = 0
+	}
+
+	return chars, shifts
+}
+
+// encodeOptimized finds the cheapest register for every character by dynamic programming over the registers
+func encodeOptimized(msg string, options OptimizeOptions, ver version) ([]byte, error) {
+	t, err := lookupTables(ver)
+	if err != nil {
+		return nil, err
+	}
+	n := len(t.registers)
+
+	type choice struct {
+		char  rune
+		codes []byte
+		ok    []bool
+	}
+
+	var choices []choice
+	position := -1
+	for _, char := range msg {
+		position++
+		c := choice{char: char, codes: make([]byte, n), ok: make([]bool, n)}
+		found := false
+		for charset := range t.registers {
+			code, shiftedCharset, err := encodeChar(char, Charset(charset), ver)
+			if err == nil && shiftedCharset == Charset(charset) {
+				c.codes[charset] = code
+				c.ok[charset] = true
+				found = true
+			}
+		}
+
+		if !found {
+			if options.IgnoreError {
+				continue
+			}
+			return nil, &CodecError{Kind: InvalidChar, Char: char, Position: position}
+		}
+		choices = append(choices, c)
+	}
+
+	// paths[from][to] are the shift codes between 2 registers
+	paths := make([][][]byte, n)
+	for from := range paths {
+		paths[from] = make([][]byte, n)
+		for to := range paths[from] {
+			path, err := t.shiftPath(Charset(from), Charset(to))
+			if err != nil {
+				path = nil
+			}
+			paths[from][to] = path
+		}
+	}
+	reachable := func(from, to Charset) bool {
+		return from == to || len(paths[from][to]) > 0
+	}
+
+	const inf = int(^uint(0) >> 1)
+	// cost[i][r] is the number of codes needed for the first i characters, ending with character i-1 printed in register r
+	// from[i][r] is the register the receiver was in before character i-1
+	cost := make([][]int, len(choices)+1)
+	from := make([][]Charset, len(choices)+1)
+	// the sequence starts with a shift code anyway, so it's free to start in a register reached by a single shift code
+	cost[0] = make([]int, n)
+	for charset := range cost[0] {
+		cost[0][charset] = len(startCodes(ver, Charset(charset))) - len(startCodes(ver, Letters))
+	}
+
+	after := func(c choice, charset Charset) Charset {
+		if options.UnshiftOnSpace && c.char == ' ' {
+			return Letters
+		}
+		return charset
+	}
+
+	for i, c := range choices {
+		cost[i+1] = make([]int, n)
+		from[i+1] = make([]Charset, n)
+		for charset := range cost[i+1] {
+			cost[i+1][charset] = inf
+		}
+		for prev := range t.registers {
+			if cost[i][prev] == inf {
+				continue
+			}
+			state := Charset(prev)
+			if i > 0 {
+				state = after(choices[i-1], Charset(prev))
+			}
+			for charset := range t.registers {
+				if !c.ok[charset] || !reachable(state, Charset(charset)) {
+					continue
+				}
+				m := cost[i][prev] + 1 + len(paths[state][charset])
+				// on a tie, shift as late as possible like the naive encoder
+				if m < cost[i+1][charset] || (m == cost[i+1][charset] && Charset(charset) != state) {
+					cost[i+1][charset] = m
+					from[i+1][charset] = Charset(prev)
+				}
+			}
+		}
+	}
+
+	// walk back the cheapest path
+	registers := make([]Charset, len(choices))
+	last := Letters
+	if len(choices) > 0 {
+		for charset, m := range cost[len(choices)] {
+			if m < cost[len(choices)][last] {
+				last = Charset(charset)
+			}
+		}
+	}
+	for i := len(choices); i > 0; i-- {
+		registers[i-1] = last
+		last = from[i][last]
+	}
+
+	codes := startCodes(ver, last)
+	state := last
+	for i, c := range choices {
+		codes = append(codes, paths[state][registers[i]]...)
+		codes = append(codes, c.codes[registers[i]])
+		state = after(c, registers[i])
+	}
+
+	return codes, nil
+}
+
+// encodeNaive is encode, with USOS it shifts back to Figures whenever a space has unshifted the receiver
+func encodeNaive(msg string, options OptimizeOptions, ver version) ([]byte, error) {
+	if !options.UnshiftOnSpace {
+		return encode(msg, options.IgnoreError, ver)
+	}
+
+	codes := startCodes(ver, Letters)
+	currentCharset := Letters
+	for _, char := range msg {
+		code, shiftedCharset, err := encodeChar(char, currentCharset, ver)
+		if err != nil {
+			if options.IgnoreError {
+				continue
+			}
+			return nil, err
+		}
+
+		if currentCharset != shiftedCharset {
+			shifters, err := shiftPath(ver, currentCharset, shiftedCharset)
+			if err != nil {
+				return nil, err
+			}
+			currentCharset = shiftedCharset
+			codes = append(codes, shifters...)
+		}
+		codes = append(codes, code)
+
+		if char == ' ' {
+			currentCharset = Letters
+		}
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,102 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEncodeOptimized(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		msg        string
+		options    OptimizeOptions
+		expect     []byte
+		failedText string
+	}{
+		{
+			caseName:   "test message starting with figures",
+			msg:        "12 AB",
+			expect:     []byte{0, 27, 23, 19, 4, 31, 3, 25},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 27, 23, 19, 4, 31, 3, 25}),
+		},
+		{
+			caseName:   "test message starting with letters",
+			msg:        "AB 12",
+			expect:     []byte{0, 31, 3, 25, 4, 27, 23, 19},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 31, 3, 25, 4, 27, 23, 19}),
+		},
+		{
+			caseName:   "test unshift on space",
+			msg:        "1 2",
+			options:    OptimizeOptions{UnshiftOnSpace: true},
+			expect:     []byte{0, 27, 23, 4, 27, 19},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 27, 23, 4, 27, 19}),
+		},
+		{
+			caseName:   "test invalid char, ignore error",
+			msg:        "1$2",
+			options:    OptimizeOptions{IgnoreError: true},
+			expect:     []byte{0, 27, 23, 19},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 27, 23, 19}),
+		},
+	}
+
+	c := NewITA2(false)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			codes, err := EncodeOptimized(c, tc.msg, tc.options)
+			if err != nil || string(codes) != string(tc.expect) {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+			}
+
+			var str string
+			if tc.options.UnshiftOnSpace {
+				str, err = DecodeUnshiftOnSpace(c, codes, false)
+			} else {
+				str, err = c.Decode(codes)
+			}
+			if expect := map[bool]string{true: "12", false: tc.msg}[tc.options.IgnoreError]; err != nil || str != expect {
+				t.Errorf("expect %q to round trip, got %q, %v", expect, str, err)
+			}
+		})
+	}
+
+	if _, err := EncodeOptimized(c, "1$2", OptimizeOptions{}); err == nil {
+		t.Errorf("expect an error for invalid char")
+	}
+}
+
+func TestCompareEncoding(t *testing.T) {
+	tt := []struct {
+		caseName string
+		msg      string
+		options  OptimizeOptions
+		expect   EncodeStats
+	}{
+		{
+			caseName: "test figures first",
+			msg:      "73 DE K1",
+			expect:   EncodeStats{Chars: 8, NaiveCodes: 13, NaiveShifts: 3, OptimizedCodes: 12, OptimizedShifts: 2},
+		},
+		{
+			caseName: "test letters first",
+			msg:      "DE K1",
+			expect:   EncodeStats{Chars: 5, NaiveCodes: 8, NaiveShifts: 1, OptimizedCodes: 8, OptimizedShifts: 1},
+		},
+		{
+			caseName: "test unshift on space",
+			msg:      "1 2 3",
+			options:  OptimizeOptions{UnshiftOnSpace: true},
+			expect:   EncodeStats{Chars: 5, NaiveCodes: 10, NaiveShifts: 3, OptimizedCodes: 9, OptimizedShifts: 2},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			stats, err := CompareEncoding(NewITA2(false), tc.msg, tc.options)
+			if err != nil || stats != tc.expect {
+				t.Errorf("expect %+v, got %+v, %v", tc.expect, stats, err)
+			}
+		})
+	}
+}
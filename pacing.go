@@ -0,0 +1,82 @@
+/*
+ * Line speed pacing. Each character on an asynchronous teleprinter line takes 1 start bit, 5 data bits and the stop bits,
+ * e.g. at 45.45 baud with 1.5 stop bits a character lasts 7.5/45.45 s, about 6 characters per second.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Clock is the time source of a paced writer, tests can inject a fake one
+type Clock interface {
+	Now() time.Time
+	Sleep(time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// CharDuration returns the time a character takes on the line
+func CharDuration(baud float64, stopBits float64) time.Duration {
+	return time.Duration((1 + 5 + stopBits) / baud * float64(time.Second))
+}
+
+// TransmissionTime estimates the time taken to send codes(e.g. the output of Encode) on the line
+func TransmissionTime(codes []byte, baud float64, stopBits float64) time.Duration {
+	return time.Duration(len(codes)) * CharDuration(baud, stopBits)
+}
+
+type pacedWriter struct {
+	w        io.Writer
+	clock    Clock
+	charTime time.Duration
+	next     time.Time
+}
+
+// NewPacedWriter wraps w so codes are written one at a time at the character rate of the line,
+// a nil clock uses the system clock
+func NewPacedWriter(w io.Writer, baud float64, stopBits float64, clock Clock) (*pacedWriter, error) {
+	if baud <= 0 || stopBits < 0 {
+		return nil, fmt.Errorf("Invalid line speed: %v baud, %v stop bits", baud, stopBits)
+	}
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	return &pacedWriter{
+		w:        w,
+		clock:    clock,
+		charTime: CharDuration(baud, stopBits),
+	}, nil
+}
+
+// Write writes codes paced at the character rate, the schedule carries over between calls so pacing doesn't drift
+func (pw *pacedWriter) Write(codes []byte) (int, error) {
+	for i := range codes {
+		now := pw.clock.Now()
+		if pw.next.Before(now) {
+			// the line has been idle, start from now
+			pw.next = now
+		} else if wait := pw.next.Sub(now); wait > 0 {
+			pw.clock.Sleep(wait)
+		}
+
+		if _, err := pw.w.Write(codes[i : i+1]); err != nil {
+			return i, err
+		}
+		pw.next = pw.next.Add(pw.charTime)
+	}
+
+	return len(codes), nil
+}
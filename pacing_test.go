@@ -0,0 +1,102 @@
+package baudot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.slept = append(c.slept, d)
+	c.now = c.now.Add(d)
+}
+
+func TestCharDuration(t *testing.T) {
+	tt := []struct {
+		caseName string
+		baud     float64
+		stopBits float64
+		expect   time.Duration
+	}{
+		{
+			caseName: "test 45.45 baud with 1.5 stop bits",
+			baud:     45.45,
+			stopBits: 1.5,
+			expect:   165016501,
+		},
+		{
+			caseName: "test 50 baud with 1.5 stop bits",
+			baud:     50,
+			stopBits: 1.5,
+			expect:   150 * time.Millisecond,
+		},
+		{
+			caseName: "test 75 baud with 1 stop bit",
+			baud:     75,
+			stopBits: 1,
+			expect:   93333333,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			if d := CharDuration(tc.baud, tc.stopBits); d != tc.expect {
+				t.Errorf("expect %v, got %v", tc.expect, d)
+			}
+		})
+	}
+
+	codes, _ := NewITA2(false).Encode("RYRYRYRY")
+	if d := TransmissionTime(codes, 50, 1.5); d != 10*150*time.Millisecond {
+		t.Errorf("expect 1.5s, got %v", d)
+	}
+}
+
+func TestPacedWriter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var buf bytes.Buffer
+	pw, err := NewPacedWriter(&buf, 50, 1.5, clock)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	start := clock.Now()
+	codes, _ := NewITA2(false).Encode("RY")
+	if n, err := pw.Write(codes); err != nil || n != len(codes) {
+		t.Fatalf("expect %d codes written, got %v, %v", len(codes), n, err)
+	}
+	if !bytes.Equal(buf.Bytes(), codes) {
+		t.Errorf("expect %v, got %v", codes, buf.Bytes())
+	}
+	// the last code is written after len-1 character periods
+	if elapsed := clock.Now().Sub(start); elapsed != 3*150*time.Millisecond {
+		t.Errorf("expect 450ms elapsed, got %v", elapsed)
+	}
+
+	// a write right after continues the schedule
+	_, _ = pw.Write([]byte{LS})
+	if elapsed := clock.Now().Sub(start); elapsed != 4*150*time.Millisecond {
+		t.Errorf("expect 600ms elapsed, got %v", elapsed)
+	}
+
+	// after an idle period the first code is written at once
+	clock.now = clock.now.Add(time.Second)
+	slept := len(clock.slept)
+	_, _ = pw.Write([]byte{LS})
+	if len(clock.slept) != slept {
+		t.Errorf("expect no wait after idle line, got %v", clock.slept[slept:])
+	}
+
+	if _, err := NewPacedWriter(&buf, 0, 1.5, nil); err == nil {
+		t.Errorf("expect an error for invalid baud rate")
+	}
+}
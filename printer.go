@@ -0,0 +1,163 @@
+/*
+ * Teleprinter page emulator, renders a code stream the way a page printer prints it:
+ * CR returns the carriage without feeding the paper(so the next characters overprint the line), LF feeds the paper
+ * without moving the carriage, LTRS/FIGS and other controls don't advance the carriage,
+ * and the carriage either jams at the right margin(every further character strikes the last column) or wraps to a new line.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"strings"
+)
+
+type MarginMode byte
+
+const (
+	// characters beyond the right margin overprint the last column
+	MarginJam MarginMode = 0
+	// characters beyond the right margin start a new line
+	MarginWrap MarginMode = 1
+)
+
+const (
+	CarriageWidth69 = 69
+	CarriageWidth72 = 72
+)
+
+// Cell is a print position, it holds every character struck there in order
+type Cell []rune
+
+type printer struct {
+	ver     version
+	width   int
+	margin  MarginMode
+	charset Charset
+	row     int
+	column  int
+	page    [][]Cell
+}
+
+// NewPrinter creates a printer for the variant of codec with a carriage of width columns
+func NewPrinter(codec Codec, width int, margin MarginMode) (*printer, error) {
+	v, ok := codec.(versioned)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported codec: %T", codec)
+	}
+	if width <= 0 {
+		return nil, fmt.Errorf("Invalid width: %d", width)
+	}
+
+	return &printer{
+		ver:    v.ver(),
+		width:  width,
+		margin: margin,
+		page:   [][]Cell{{}},
+	}, nil
+}
+
+// Print feeds codes to the printer, the register and carriage position are kept between calls
+func (p *printer) Print(codes []byte) error {
+	for _, code := range codes {
+		ch, shiftedCharset, err := decodeChar(code, p.charset, p.ver)
+		if err != nil {
+			return err
+		}
+		if shiftedCharset != p.charset {
+			p.charset = shiftedCharset
+			continue
+		}
+
+		switch {
+		case ch == '\r':
+			p.column = 0
+		case ch == '\n':
+			p.feed()
+		case ch == ' ':
+			p.advance()
+		case ch < ' ':
+			// NULL, bell, WRU and other controls neither print nor move the carriage
+		default:
+			p.strike(ch)
+		}
+	}
+
+	return nil
+}
+
+// Position returns the line and column of the carriage
+func (p *printer) Position() (int, int) {
+	return p.row, p.column
+}
+
+// Page returns the printed page, one slice of cells per line, trailing blank cells are omitted
+func (p *printer) Page() [][]Cell {
+	page := make([][]Cell, len(p.page))
+	for i, line := range p.page {
+		page[i] = make([]Cell, len(line))
+		for j, cell := range line {
+			page[i][j] = append(Cell{}, cell...)
+		}
+	}
+
+	return page
+}
+
+// String renders the page as text showing the last character struck in each position
+func (p *printer) String() string {
+	lines := make([]string, len(p.page))
+	for i, line := range p.page {
+		var sb strings.Builder
+		for _, cell := range line {
+			if len(cell) == 0 {
+				sb.WriteRune(' ')
+			} else {
+				sb.WriteRune(cell[len(cell)-1])
+			}
+		}
+		lines[i] = sb.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (p *printer) feed() {
+	p.row++
+	for len(p.page) <= p.row {
+		p.page = append(p.page, []Cell{})
+	}
+}
+
+// advance moves the carriage one column, a carriage at the margin stays there
+func (p *printer) advance() {
+	p.margins()
+	p.column++
+}
+
+func (p *printer) strike(ch rune) {
+	p.margins()
+
+	line := p.page[p.row]
+	for len(line) <= p.column {
+		line = append(line, Cell{})
+	}
+	line[p.column] = append(line[p.column], ch)
+	p.page[p.row] = line
+
+	p.column++
+}
+
+// margins handles a carriage which has reached the right margin before a character or space
+func (p *printer) margins() {
+	if p.column < p.width {
+		return
+	}
+
+	if p.margin == MarginWrap {
+		p.column = 0
+		p.feed()
+	} else {
+		p.column = p.width - 1
+	}
+}
@@ -0,0 +1,98 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPrinter(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		width      int
+		margin     MarginMode
+		msg        string
+		expect     string
+		failedText string
+	}{
+		{
+			caseName:   "test CR LF",
+			width:      CarriageWidth69,
+			msg:        "AB\r\nCD",
+			expect:     "AB\nCD",
+			failedText: "expect 'AB\\nCD', got %v",
+		},
+		{
+			caseName:   "test LF without CR",
+			width:      CarriageWidth69,
+			msg:        "AB\nCD",
+			expect:     "AB\n  CD",
+			failedText: "expect 'AB\\n  CD', got %v",
+		},
+		{
+			caseName:   "test CR without LF overprints",
+			width:      CarriageWidth69,
+			msg:        "ABC\r  D",
+			expect:     "ABD",
+			failedText: "expect 'ABD', got %v",
+		},
+		{
+			caseName:   "test shifts don't advance the carriage",
+			width:      CarriageWidth69,
+			msg:        "A1B2",
+			expect:     "A1B2",
+			failedText: "expect 'A1B2', got %v",
+		},
+		{
+			caseName:   "test jam at margin",
+			width:      4,
+			margin:     MarginJam,
+			msg:        "ABCDEF\r\nG",
+			expect:     "ABCF\nG",
+			failedText: "expect 'ABCF\\nG', got %v",
+		},
+		{
+			caseName:   "test wrap at margin",
+			width:      4,
+			margin:     MarginWrap,
+			msg:        "ABCDEF\r\nG",
+			expect:     "ABCD\nEF\nG",
+			failedText: "expect 'ABCD\\nEF\\nG', got %v",
+		},
+	}
+
+	c := NewITA2(false)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			p, err := NewPrinter(c, tc.width, tc.margin)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			codes, _ := c.Encode(tc.msg)
+			if err := p.Print(codes); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if str := p.String(); str != tc.expect {
+				t.Errorf(tc.failedText, fmt.Sprintf("%q", str))
+			}
+		})
+	}
+}
+
+func TestPrinterOverstrike(t *testing.T) {
+	c := NewITA2(false)
+	p, _ := NewPrinter(c, 4, MarginJam)
+	codes, _ := c.Encode("ABCDE\rX")
+	_ = p.Print(codes)
+
+	page := p.Page()
+	if len(page) != 1 || string(page[0][0]) != "AX" || string(page[0][3]) != "DE" {
+		t.Errorf("expect overstrikes 'AX' and 'DE', got %q", page)
+	}
+	if row, column := p.Position(); row != 0 || column != 1 {
+		t.Errorf("expect carriage at 0, 1, got %v, %v", row, column)
+	}
+
+	if err := p.Print([]byte{100}); err == nil {
+		t.Errorf("expect an error for invalid code")
+	}
+}
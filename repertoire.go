@@ -0,0 +1,107 @@
+/*
+ * Repertoire queries, e.g. to warn about characters a variant can't send before sending a message.
+ * The answers come from the encoding tables, so they agree with Encode: aliases(e.g. similar glyphs) are listed in the repertoire
+ * and the characters a codec rewrites before encoding(e.g. voiced kana) are supported.
+ */
+
+package baudot
+
+import (
+	"errors"
+	"sort"
+)
+
+// UnsupportedChar is a character of a message the variant can't encode, Position is the index of the rune in the message
+type UnsupportedChar struct {
+	Position int
+	Char     rune
+}
+
+// repertoire returns the characters encoded in charset by code, null excluded
+func repertoire(ver version, charset Charset) []rune {
+	t, err := lookupTables(ver)
+	if err != nil || int(charset) >= len(t.registers) {
+		return nil
+	}
+
+	var chars []rune
+	for char, values := range t.charmap {
+		if values[charset] != -1 && char != '\u0000' {
+			chars = append(chars, char)
+		}
+	}
+	sort.Slice(chars, func(i, j int) bool {
+		a, b := t.charmap[chars[i]][charset], t.charmap[chars[j]][charset]
+		if a != b {
+			return a < b
+		}
+		return chars[i] < chars[j]
+	})
+
+	return chars
+}
+
+// unsupported returns the characters of msg the variant can't encode, normalize is what the codec does to a message before encoding it
+func unsupported(msg string, ver version, normalize func(string) string) []UnsupportedChar {
+	t, err := lookupTables(ver)
+	if err != nil {
+		return nil
+	}
+
+	var chars []UnsupportedChar
+	position := 0
+	for _, char := range msg {
+		for _, normalized := range normalize(string(char)) {
+			if _, ok := t.charmap[normalized]; !ok {
+				chars = append(chars, UnsupportedChar{Position: position, Char: char})
+				break
+			}
+		}
+		position++
+	}
+
+	return chars
+}
+
+// unnormalizedPosition moves the position of an invalid character from the normalized message to msg
+func unnormalizedPosition(err error, msg string, ver version, normalize func(string) string) error {
+	var e *CodecError
+	if errors.As(err, &e) && e.Kind == InvalidChar {
+		if chars := unsupported(msg, ver, normalize); len(chars) > 0 {
+			e.Position = chars[0].Position
+		}
+	}
+
+	return err
+}
+
+// encodedLen returns the number of codes encode returns for msg, without encoding it
+func encodedLen(msg string, ignoreError bool, ver version) (int, error) {
+	n := len(startCodes(ver, Letters))
+	currentCharset := Letters
+	for _, char := range msg {
+		_, shiftedCharset, err := encodeChar(char, currentCharset, ver)
+		if err != nil {
+			if ignoreError {
+				continue
+			}
+			return 0, err
+		}
+
+		if shiftedCharset != currentCharset {
+			shifters, err := shiftPath(ver, currentCharset, shiftedCharset)
+			if err != nil {
+				return 0, err
+			}
+			n += len(shifters)
+			currentCharset = shiftedCharset
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+func identity(msg string) string {
+	return msg
+}
@@ -0,0 +1,82 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRepertoire(t *testing.T) {
+	tt := []struct {
+		caseName string
+		chars    []rune
+		expect   string
+	}{
+		{caseName: "test ITA2 letters", chars: NewITA2(false).Repertoire(Letters), expect: "E\nA SIU\rDRJNFCKTZLWHYPQOBGMXV"},
+		{caseName: "test ITA2 figures", chars: NewITA2(false).Repertoire(Figures), expect: "3\n- '87\r\u00054\u0007,!:(5+)2£6019?&./="},
+		{caseName: "test ITA3 letters", chars: NewITA3(false).Repertoire(Letters), expect: "E\nA SIU\rDRJNFCKTZLWHYPQOBGMXV"},
+		{caseName: "test katakana", chars: NewKatakana(false).Repertoire(Katakana), expect: "ア\nイ ウエオ\rカキクケコサシスセソタチツテトナニヌネノハ"},
+		{caseName: "test unknown register", chars: NewITA2(false).Repertoire(Katakana), expect: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			if string(tc.chars) != tc.expect {
+				t.Errorf("expect %q, got %q", tc.expect, string(tc.chars))
+			}
+		})
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	tt := []struct {
+		caseName string
+		codec    interface {
+			CanEncode(string) bool
+			Unsupported(string) []UnsupportedChar
+		}
+		msg    string
+		expect []UnsupportedChar
+	}{
+		{caseName: "test supported", codec: NewITA2(false), msg: "HELLO, WORLD", expect: nil},
+		{caseName: "test lower case and dollar", codec: NewITA2(false), msg: "Pay $5", expect: []UnsupportedChar{{1, 'a'}, {2, 'y'}, {4, '$'}}},
+		{caseName: "test dollar in US TTY", codec: NewUSTTY(false), msg: "PAY $5", expect: nil},
+		{caseName: "test positions are runes", codec: NewITA2(false), msg: "£€£", expect: []UnsupportedChar{{1, '€'}}},
+		{caseName: "test ITA3", codec: NewITA3(false), msg: "PAY $5", expect: []UnsupportedChar{{4, '$'}}},
+		{caseName: "test weather alias", codec: NewWeather(false), msg: "⬆ ↑", expect: nil},
+		{caseName: "test voiced kana", codec: NewKatakana(false), msg: "ガッコウ 東京", expect: []UnsupportedChar{{5, '東'}, {6, '京'}}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			got := tc.codec.Unsupported(tc.msg)
+			if fmt.Sprint(got) != fmt.Sprint(tc.expect) {
+				t.Errorf("expect %v, got %v", tc.expect, got)
+			}
+			if tc.codec.CanEncode(tc.msg) != (len(tc.expect) == 0) {
+				t.Errorf("expect CanEncode %v", len(tc.expect) == 0)
+			}
+		})
+	}
+}
+
+func TestEncodedLen(t *testing.T) {
+	codecs := []interface {
+		Codec
+		EncodedLen(string) (int, error)
+	}{NewITA1(true), NewITA2(true), NewUSTTY(true), NewMurray(true), NewWeather(true), NewKatakana(true), NewITA3(true)}
+	msgs := []string{"", "A", "1", "RYRY 1234 RYRY", "Pay $5, 3/4 ½", "ガッコウ ニ イク 3"}
+
+	for _, c := range codecs {
+		for _, msg := range msgs {
+			codes, _ := c.Encode(msg)
+			n, err := c.EncodedLen(msg)
+			if err != nil || n != len(codes) {
+				t.Errorf("%T %q: expect %d, got %d, %v", c, msg, len(codes), n, err)
+			}
+		}
+	}
+
+	if _, err := NewITA2(false).EncodedLen("$"); err == nil {
+		t.Errorf("expect error for '$'")
+	}
+}
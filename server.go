@@ -0,0 +1,492 @@
+/*
+ * HTTP service exposing the codecs as JSON endpoints, served by cmd/baudotd:
+ *
+ *	GET  /variants  variants with their registers and repertoires
+ *	POST /encode    {"variant": "ITA2", "text": "HELLO", "optimize": false, "unshiftOnSpace": false, "ignoreError": false}
+ *	POST /decode    {"variant": "ITA2", "codes": [31, 20, 1] or "hex": "1f1401", "unshiftOnSpace": false, "ignoreError": false}
+ *	POST /render    {"codes": [...] or "hex": "...", "format": "text" or "png", "scale": 16}, or text and variant to render the encoded text
+ *	POST /detect    {"text": "..."} or {"codes": [...]}, the variants able to encode the text or decode the codes
+ *
+ * Errors are {"error": {"code": "invalid_char", "message": "Invalid Char: @", "position": 3}}, the code is one of
+ * bad_request, invalid_char, invalid_code, unknown_variant, not_found, method_not_allowed and request_too_large.
+ */
+
+package baudot
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+const (
+	defaultMaxBodySize    = 64 << 10
+	defaultMaxRenderCodes = 4096
+	// a gray image of 4M pixels is 4MB
+	defaultMaxRenderPixels = 4 << 20
+	defaultTapeScale       = 16
+	maxTapeScale           = 64
+)
+
+type HandlerOptions struct {
+	// MaxBodySize is the largest request body in bytes, 0 means 64KiB
+	MaxBodySize int64
+	// MaxRenderCodes is the longest tape rendered, 0 means 4096 codes
+	MaxRenderCodes int
+	// MaxRenderPixels is the largest PNG rendered, codes and scale together, 0 means 4M pixels
+	MaxRenderPixels int
+}
+
+type handler struct {
+	options HandlerOptions
+	mux     *http.ServeMux
+}
+
+type apiError struct {
+	status   int
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Position *int   `json:"position,omitempty"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+type codesRequest struct {
+	Codes []int  `json:"codes,omitempty"`
+	Hex   string `json:"hex,omitempty"`
+}
+
+type encodeRequest struct {
+	Variant        string `json:"variant"`
+	Text           string `json:"text"`
+	Optimize       bool   `json:"optimize"`
+	UnshiftOnSpace bool   `json:"unshiftOnSpace"`
+	IgnoreError    bool   `json:"ignoreError"`
+}
+
+type encodeResponse struct {
+	Variant string `json:"variant"`
+	Codes   []int  `json:"codes"`
+	Hex     string `json:"hex"`
+	Chars   int    `json:"chars"`
+	Shifts  int    `json:"shifts"`
+}
+
+type decodeRequest struct {
+	codesRequest
+	Variant        string `json:"variant"`
+	UnshiftOnSpace bool   `json:"unshiftOnSpace"`
+	IgnoreError    bool   `json:"ignoreError"`
+}
+
+type decodeResponse struct {
+	Variant string       `json:"variant"`
+	Text    string       `json:"text"`
+	Codes   []codeDetail `json:"codes"`
+	// Register the receiver is in after the codes
+	Register string `json:"register"`
+}
+
+// codeDetail describes how a receiver reads a code
+type codeDetail struct {
+	Code     int    `json:"code"`
+	Register string `json:"register"`
+	Char     string `json:"char,omitempty"`
+	Shift    string `json:"shift,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type renderRequest struct {
+	codesRequest
+	Variant string `json:"variant"`
+	Text    string `json:"text"`
+	Format  string `json:"format"`
+	Scale   int    `json:"scale"`
+}
+
+type detectRequest struct {
+	codesRequest
+	Text string `json:"text"`
+}
+
+type candidate struct {
+	Variant string `json:"variant"`
+	Text    string `json:"text,omitempty"`
+	Length  int    `json:"length,omitempty"`
+}
+
+type variantInfo struct {
+	Name      string         `json:"name"`
+	Registers []registerInfo `json:"registers"`
+}
+
+type registerInfo struct {
+	Name  string `json:"name"`
+	Chars string `json:"chars"`
+}
+
+// NewHandler creates the HTTP handler of the service, the national versions of ITA2 are registered with it
+func NewHandler(options HandlerOptions) (http.Handler, error) {
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = defaultMaxBodySize
+	}
+	if options.MaxRenderCodes <= 0 {
+		options.MaxRenderCodes = defaultMaxRenderCodes
+	}
+	if options.MaxRenderPixels <= 0 {
+		options.MaxRenderPixels = defaultMaxRenderPixels
+	}
+	for _, profile := range NationalProfiles() {
+		if _, err := NewITA2National(profile, false); err != nil {
+			return nil, err
+		}
+	}
+
+	h := &handler{options: options, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/variants", h.method(http.MethodGet, h.variants))
+	h.mux.HandleFunc("/encode", h.method(http.MethodPost, h.encode))
+	h.mux.HandleFunc("/decode", h.method(http.MethodPost, h.decode))
+	h.mux.HandleFunc("/render", h.method(http.MethodPost, h.render))
+	h.mux.HandleFunc("/detect", h.method(http.MethodPost, h.detect))
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &apiError{status: http.StatusNotFound, Code: "not_found", Message: "Not found: " + r.URL.Path})
+	})
+
+	return h, nil
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.options.MaxBodySize)
+	h.mux.ServeHTTP(w, r)
+}
+
+// method wraps an endpoint accepting a single method and writing the value or the error it returns as JSON
+func (h *handler) method(method string, endpoint func(w http.ResponseWriter, r *http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, &apiError{status: http.StatusMethodNotAllowed, Code: "method_not_allowed", Message: "Method not allowed: " + r.Method})
+			return
+		}
+
+		v, err := endpoint(w, r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if v != nil {
+			writeJSON(w, http.StatusOK, v)
+		}
+	}
+}
+
+func (h *handler) variants(w http.ResponseWriter, r *http.Request) (any, error) {
+	var infos []variantInfo
+	for _, ver := range versions() {
+		t, err := lookupTables(ver)
+		if err != nil {
+			return nil, err
+		}
+		info := variantInfo{Name: t.name}
+		for charset, name := range t.names {
+			info.Registers = append(info.Registers, registerInfo{Name: name, Chars: string(repertoire(ver, Charset(charset)))})
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+func (h *handler) encode(w http.ResponseWriter, r *http.Request) (any, error) {
+	var req encodeRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+	ver, codec, err := variantCodec(req.Variant, req.IgnoreError)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []byte
+	if req.Optimize || req.UnshiftOnSpace {
+		codes, err = EncodeOptimized(codec, req.Text, OptimizeOptions{UnshiftOnSpace: req.UnshiftOnSpace, IgnoreError: req.IgnoreError})
+	} else {
+		codes, err = codec.Encode(req.Text)
+	}
+	if err != nil {
+		return nil, codecError(err)
+	}
+
+	res := encodeResponse{Variant: req.Variant, Codes: ints(codes), Hex: hex.EncodeToString(codes)}
+	details, _ := readCodes(codes, ver, req.UnshiftOnSpace)
+	for _, detail := range details {
+		switch {
+		case detail.Shift != "":
+			res.Shifts++
+		case detail.Char != "" && detail.Char != "NUL":
+			res.Chars++
+		}
+	}
+
+	return res, nil
+}
+
+func (h *handler) decode(w http.ResponseWriter, r *http.Request) (any, error) {
+	var req decodeRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+	codes, err := req.codes()
+	if err != nil {
+		return nil, err
+	}
+	ver, codec, err := variantCodec(req.Variant, req.IgnoreError)
+	if err != nil {
+		return nil, err
+	}
+
+	var text string
+	if req.UnshiftOnSpace {
+		text, err = DecodeUnshiftOnSpace(codec, codes, req.IgnoreError)
+	} else {
+		text, err = codec.Decode(codes)
+	}
+	if err != nil {
+		return nil, codecError(err)
+	}
+
+	details, register := readCodes(codes, ver, req.UnshiftOnSpace)
+
+	return decodeResponse{Variant: req.Variant, Text: text, Codes: details, Register: register}, nil
+}
+
+func (h *handler) render(w http.ResponseWriter, r *http.Request) (any, error) {
+	var req renderRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+
+	codes, err := req.codes()
+	if err != nil {
+		return nil, err
+	}
+	if req.Text != "" {
+		if len(codes) > 0 {
+			return nil, badRequest("Expect text or codes, not both")
+		}
+		_, codec, err := variantCodec(req.Variant, false)
+		if err != nil {
+			return nil, err
+		}
+		if codes, err = codec.Encode(req.Text); err != nil {
+			return nil, codecError(err)
+		}
+	}
+	if len(codes) > h.options.MaxRenderCodes {
+		return nil, &apiError{status: http.StatusRequestEntityTooLarge, Code: "request_too_large", Message: fmt.Sprintf("Tape longer than %d codes", h.options.MaxRenderCodes)}
+	}
+
+	switch req.Format {
+	case "", "text":
+		tape, err := TapeText(codes)
+		if err != nil {
+			return nil, codecError(err)
+		}
+		return map[string]string{"tape": tape}, nil
+	case "png":
+		if req.Scale == 0 {
+			req.Scale = defaultTapeScale
+		}
+		if req.Scale < 4 || req.Scale > maxTapeScale {
+			return nil, badRequest(fmt.Sprintf("Invalid scale: %d", req.Scale))
+		}
+		if pixels := tapePNGPixels(len(codes), req.Scale); pixels > h.options.MaxRenderPixels {
+			return nil, &apiError{status: http.StatusRequestEntityTooLarge, Code: "request_too_large", Message: fmt.Sprintf("Tape image of %d pixels, larger than %d", pixels, h.options.MaxRenderPixels)}
+		}
+		w.Header().Set("Content-Type", "image/png")
+		return nil, WriteTapePNG(w, codes, req.Scale)
+	}
+
+	return nil, badRequest("Invalid format: " + req.Format)
+}
+
+func (h *handler) detect(w http.ResponseWriter, r *http.Request) (any, error) {
+	var req detectRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+	codes, err := req.codes()
+	if err != nil {
+		return nil, err
+	}
+	if (req.Text == "") == (len(codes) == 0) {
+		return nil, badRequest("Expect text or codes")
+	}
+
+	candidates := []candidate{}
+	for _, ver := range versions() {
+		t, _ := lookupTables(ver)
+		codec := codecOf(ver, false)
+		if req.Text != "" {
+			if codes, err := codec.Encode(req.Text); err == nil {
+				candidates = append(candidates, candidate{Variant: t.name, Length: len(codes)})
+			}
+		} else if text, err := codec.Decode(codes); err == nil {
+			candidates = append(candidates, candidate{Variant: t.name, Text: text})
+		}
+	}
+	// the variants encoding the text in fewer codes first
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Length < candidates[j].Length })
+
+	return candidates, nil
+}
+
+// codes returns the codes of the request, given as numbers or in hex
+func (req codesRequest) codes() ([]byte, error) {
+	if len(req.Codes) > 0 && req.Hex != "" {
+		return nil, badRequest("Expect codes or hex, not both")
+	}
+	if req.Hex != "" {
+		codes, err := hex.DecodeString(req.Hex)
+		if err != nil {
+			return nil, badRequest("Invalid hex: " + err.Error())
+		}
+		return codes, nil
+	}
+
+	codes := make([]byte, len(req.Codes))
+	for i, code := range req.Codes {
+		if code < 0 || code > 0xFF {
+			position := i
+			return nil, &apiError{status: http.StatusUnprocessableEntity, Code: "invalid_code", Message: fmt.Sprintf("Invalid Code: %d", code), Position: &position}
+		}
+		codes[i] = byte(code)
+	}
+
+	return codes, nil
+}
+
+// readCodes reads codes like a receiver, it returns how each code is read and the register the receiver ends in
+func readCodes(codes []byte, ver version, unshiftOnSpace bool) ([]codeDetail, string) {
+	t, err := lookupTables(ver)
+	if err != nil {
+		return nil, ""
+	}
+
+	details := make([]codeDetail, 0, len(codes))
+	charset := Letters
+	for _, code := range codes {
+		detail := codeDetail{Code: int(code), Register: t.names[charset]}
+		if shifted, ok := t.shifts[charset][code]; ok {
+			detail.Shift = t.names[shifted]
+			charset = shifted
+		} else if char, ok := t.registers[charset][code]; ok {
+			detail.Char = formatChar(char)
+			if unshiftOnSpace && char == ' ' {
+				charset = Letters
+			}
+		} else {
+			detail.Error = fmt.Sprintf("Invalid Code: %d", code)
+		}
+		details = append(details, detail)
+	}
+
+	return details, t.names[charset]
+}
+
+// versions returns the registered variants in version order
+func versions() []version {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	vers := make([]version, 0, len(customTables))
+	for ver := range customTables {
+		vers = append(vers, ver)
+	}
+	sort.Slice(vers, func(i, j int) bool { return vers[i] < vers[j] })
+
+	return vers
+}
+
+func variantCodec(name string, ignoreError bool) (version, Codec, error) {
+	if name == "" {
+		return 0, nil, badRequest("Missing variant")
+	}
+	ver, err := lookupName(name)
+	if err != nil {
+		return 0, nil, &apiError{status: http.StatusNotFound, Code: "unknown_variant", Message: err.Error()}
+	}
+
+	return ver, codecOf(ver, ignoreError), nil
+}
+
+// codecError maps the errors of the codecs to API errors
+func codecError(err error) *apiError {
+	var e *apiError
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var ce *CodecError
+	if !errors.As(err, &ce) {
+		return badRequest(err.Error())
+	}
+	e = &apiError{status: http.StatusUnprocessableEntity, Message: ce.Error()}
+	switch ce.Kind {
+	case InvalidChar:
+		e.Code = "invalid_char"
+	case InvalidCode, MutilatedCode:
+		e.Code = "invalid_code"
+	default:
+		return badRequest(ce.Error())
+	}
+	if ce.Position >= 0 {
+		position := ce.Position
+		e.Position = &position
+	}
+
+	return e
+}
+
+func badRequest(msg string) *apiError {
+	return &apiError{status: http.StatusBadRequest, Code: "bad_request", Message: msg}
+}
+
+func readJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &apiError{status: http.StatusRequestEntityTooLarge, Code: "request_too_large", Message: fmt.Sprintf("Request larger than %d bytes", tooLarge.Limit)}
+		}
+		return badRequest("Invalid request: " + err.Error())
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e := codecError(err)
+	writeJSON(w, e.status, map[string]*apiError{"error": e})
+}
+
+func ints(codes []byte) []int {
+	values := make([]int, len(codes))
+	for i, code := range codes {
+		values[i] = int(code)
+	}
+
+	return values
+}
@@ -0,0 +1,310 @@
+package baudot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T, options HandlerOptions) *httptest.Server {
+	t.Helper()
+
+	handler, err := NewHandler(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestHandler(t *testing.T) {
+	server := newTestServer(t, HandlerOptions{})
+
+	tt := []struct {
+		caseName string
+		method   string
+		path     string
+		body     string
+		status   int
+		// expect is a substring of the response
+		expect string
+	}{
+		{
+			caseName: "test encode",
+			method:   http.MethodPost,
+			path:     "/encode",
+			body:     `{"variant": "ITA2", "text": "HI 5"}`,
+			status:   http.StatusOK,
+			expect:   `{"variant":"ITA2","codes":[0,31,20,6,4,27,16],"hex":"001f1406041b10","chars":4,"shifts":2}`,
+		},
+		{
+			caseName: "test encode optimized",
+			method:   http.MethodPost,
+			path:     "/encode",
+			body:     `{"variant": "ITA2", "text": "55", "optimize": true}`,
+			status:   http.StatusOK,
+			expect:   `"codes":[0,27,16,16]`,
+		},
+		{
+			caseName: "test encode invalid char",
+			method:   http.MethodPost,
+			path:     "/encode",
+			body:     `{"variant": "ITA2", "text": "AB@C"}`,
+			status:   http.StatusUnprocessableEntity,
+			expect:   `{"error":{"code":"invalid_char","message":"Invalid Char: @","position":2}}`,
+		},
+		{
+			caseName: "test encode invalid char after voiced kana",
+			method:   http.MethodPost,
+			path:     "/encode",
+			body:     `{"variant": "Katakana", "text": "ガ@", "optimize": true}`,
+			status:   http.StatusUnprocessableEntity,
+			expect:   `{"error":{"code":"invalid_char","message":"Invalid Char: @","position":1}}`,
+		},
+		{
+			caseName: "test encode unknown variant",
+			method:   http.MethodPost,
+			path:     "/encode",
+			body:     `{"variant": "ITA9", "text": "A"}`,
+			status:   http.StatusNotFound,
+			expect:   `"code":"unknown_variant"`,
+		},
+		{
+			caseName: "test encode national variant",
+			method:   http.MethodPost,
+			path:     "/encode",
+			body:     `{"variant": "ITA2 German", "text": "Ä"}`,
+			status:   http.StatusOK,
+			expect:   `"codes":[0,31,27,13]`,
+		},
+		{
+			caseName: "test decode",
+			method:   http.MethodPost,
+			path:     "/decode",
+			body:     `{"variant": "ITA2", "hex": "1f141b10"}`,
+			status:   http.StatusOK,
+			expect:   `{"variant":"ITA2","text":"H5","codes":[{"code":31,"register":"letters","shift":"letters"},{"code":20,"register":"letters","char":"H"},{"code":27,"register":"letters","shift":"figures"},{"code":16,"register":"figures","char":"5"}],"register":"figures"}`,
+		},
+		{
+			caseName: "test decode unshift on space",
+			method:   http.MethodPost,
+			path:     "/decode",
+			body:     `{"variant": "ITA2", "codes": [27, 16, 4, 16], "unshiftOnSpace": true}`,
+			status:   http.StatusOK,
+			expect:   `"text":"5 T"`,
+		},
+		{
+			caseName: "test decode invalid code",
+			method:   http.MethodPost,
+			path:     "/decode",
+			body:     `{"variant": "ITA2", "codes": [20, 40]}`,
+			status:   http.StatusUnprocessableEntity,
+			expect:   `{"error":{"code":"invalid_code","message":"Invalid Code: 40","position":1}}`,
+		},
+		{
+			caseName: "test decode ignoring errors",
+			method:   http.MethodPost,
+			path:     "/decode",
+			body:     `{"variant": "ITA2", "codes": [20, 40], "ignoreError": true}`,
+			status:   http.StatusOK,
+			expect:   `{"code":40,"register":"letters","error":"Invalid Code: 40"}`,
+		},
+		{
+			caseName: "test decode codes and hex",
+			method:   http.MethodPost,
+			path:     "/decode",
+			body:     `{"variant": "ITA2", "codes": [20], "hex": "14"}`,
+			status:   http.StatusBadRequest,
+			expect:   `"code":"bad_request"`,
+		},
+		{
+			caseName: "test render text",
+			method:   http.MethodPost,
+			path:     "/render",
+			body:     `{"codes": [1, 31]}`,
+			status:   http.StatusOK,
+			expect:   `{"tape":"|o .   |\n|oo.ooo|\n"}`,
+		},
+		{
+			caseName: "test render encoded text",
+			method:   http.MethodPost,
+			path:     "/render",
+			body:     `{"variant": "Murray", "text": "E"}`,
+			status:   http.StatusOK,
+			expect:   `{"tape":"|  .   |\n|oo.ooo|\n|o .   |\n"}`,
+		},
+		{
+			caseName: "test render invalid code",
+			method:   http.MethodPost,
+			path:     "/render",
+			body:     `{"codes": [1, 40]}`,
+			status:   http.StatusUnprocessableEntity,
+			expect:   `{"error":{"code":"invalid_code","message":"Invalid Code: 40","position":1}}`,
+		},
+		{
+			caseName: "test render invalid format",
+			method:   http.MethodPost,
+			path:     "/render",
+			body:     `{"codes": [1], "format": "gif"}`,
+			status:   http.StatusBadRequest,
+			expect:   `"code":"bad_request"`,
+		},
+		{
+			caseName: "test detect text",
+			method:   http.MethodPost,
+			path:     "/detect",
+			body:     `{"text": "½"}`,
+			status:   http.StatusOK,
+			expect:   `[{"variant":"Murray","length":4}]`,
+		},
+		{
+			caseName: "test detect codes",
+			method:   http.MethodPost,
+			path:     "/detect",
+			body:     `{"codes": [31, 20]}`,
+			status:   http.StatusOK,
+			expect:   `{"variant":"ITA2","text":"H"}`,
+		},
+		{
+			caseName: "test unknown field",
+			method:   http.MethodPost,
+			path:     "/encode",
+			body:     `{"variant": "ITA2", "txt": "A"}`,
+			status:   http.StatusBadRequest,
+			expect:   `"code":"bad_request"`,
+		},
+		{
+			caseName: "test method",
+			method:   http.MethodGet,
+			path:     "/encode",
+			status:   http.StatusMethodNotAllowed,
+			expect:   `"code":"method_not_allowed"`,
+		},
+		{
+			caseName: "test not found",
+			method:   http.MethodGet,
+			path:     "/tape",
+			status:   http.StatusNotFound,
+			expect:   `"code":"not_found"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			var body bytes.Buffer
+			body.ReadFrom(res.Body)
+			if res.StatusCode != tc.status || !strings.Contains(body.String(), tc.expect) {
+				t.Errorf("expect %d %s, got %d %s", tc.status, tc.expect, res.StatusCode, body.String())
+			}
+			if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expect JSON, got %s", ct)
+			}
+		})
+	}
+}
+
+func TestHandlerVariants(t *testing.T) {
+	server := newTestServer(t, HandlerOptions{})
+
+	res, err := http.Get(server.URL + "/variants")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var variants []variantInfo
+	if err := json.NewDecoder(res.Body).Decode(&variants); err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]variantInfo{}
+	for _, v := range variants {
+		names[v.Name] = v
+	}
+	for _, name := range []string{"ITA1", "ITA2", "US TTY", "Murray", "US weather", "Katakana", "ITA2 German"} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("expect %s listed", name)
+		}
+	}
+	if katakana := names["Katakana"]; len(katakana.Registers) != 4 || !strings.HasPrefix(katakana.Registers[2].Chars, "ア\nイ") {
+		t.Errorf("expect the registers of katakana, got %v", katakana.Registers)
+	}
+}
+
+func TestHandlerLimits(t *testing.T) {
+	server := newTestServer(t, HandlerOptions{MaxBodySize: 64, MaxRenderCodes: 2})
+
+	res, err := http.Post(server.URL+"/encode", "application/json", strings.NewReader(`{"variant": "ITA2", "text": "`+strings.Repeat("A", 100)+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expect %d, got %d", http.StatusRequestEntityTooLarge, res.StatusCode)
+	}
+
+	res, err = http.Post(server.URL+"/render", "application/json", strings.NewReader(`{"codes": [1, 2, 3]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expect %d, got %d", http.StatusRequestEntityTooLarge, res.StatusCode)
+	}
+}
+
+func TestHandlerRenderPixels(t *testing.T) {
+	server := newTestServer(t, HandlerOptions{})
+
+	// the longest tape at the largest scale, allowed separately but not together
+	codes := strings.TrimSuffix(strings.Repeat("1,", defaultMaxRenderCodes), ",")
+	for _, tc := range []struct {
+		scale  int
+		status int
+	}{{maxTapeScale, http.StatusRequestEntityTooLarge}, {4, http.StatusOK}} {
+		res, err := http.Post(server.URL+"/render", "application/json", strings.NewReader(fmt.Sprintf(`{"codes": [%s], "format": "png", "scale": %d}`, codes, tc.scale)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != tc.status {
+			t.Errorf("scale %d: expect %d, got %d", tc.scale, tc.status, res.StatusCode)
+		}
+	}
+}
+
+func TestHandlerRenderPNG(t *testing.T) {
+	handler, err := NewHandler(HandlerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/render", strings.NewReader(`{"codes": [1, 31], "format": "png", "scale": 8}`)))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expect a PNG, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 64 || size.Y != 24 {
+		t.Errorf("expect 64x24, got %v", size)
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ * SITOR-B(also known as AMTOR mode B, or FEC) is the broadcast mode of CCIR 476 used by NAVTEX.
+ * Every character is sent twice for time diversity: first in a DX position, then again in an RX position
+ * 4 characters later(DX and RX positions alternate), so the receiver can pick whichever copy passes the 4:3 ratio check.
+ */
+
+package baudot
+
+import "fmt"
+
+const (
+	// ERASURE stands for a character whose DX and RX copies were both mutilated
+	ERASURE byte = 0xFF
+	// ERASURE_CHAR is what an erasure decodes to
+	ERASURE_CHAR rune = '*'
+)
+
+const (
+	// number of DX/RX phasing pairs sent before the traffic
+	phasingLenSITORB = 8
+	// distance in DX positions between a character's DX copy and its RX copy
+	rxDelaySITORB = 2
+	// number of alpha signals in DX position which marks the end of an emission
+	endLenSITORB = 3
+)
+
+type sitorB struct {
+	ignErr bool
+}
+
+func NewSITORB(ignoreError bool) *sitorB {
+	return &sitorB{
+		ignErr: ignoreError,
+	}
+}
+
+// Encode string into an interleaved SITOR-B emission, including phasing and end of emission signals
+func (c *sitorB) Encode(msg string) ([]byte, error) {
+	codes, err := encode(msg, c.ignErr, versionITA2)
+	if err != nil {
+		return nil, err
+	}
+
+	signals, err := ToCCIR476(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Interleave(signals), nil
+}
+
+// Decode a SITOR-B emission to string, characters lost in both copies are decoded as ERASURE_CHAR
+func (c *sitorB) Decode(signals []byte) (string, error) {
+	codes, err := c.Deinterleave(signals)
+	if err != nil {
+		return "", err
+	}
+
+	var str []rune
+	currentCharset := Letters
+	for _, code := range codes {
+		if code == ERASURE {
+			str = append(str, ERASURE_CHAR)
+			continue
+		}
+
+		ch, shiftedCharset, err := decodeChar(code, currentCharset, versionITA2)
+		if err != nil {
+			if c.ignErr {
+				continue
+			}
+			return "", err
+		}
+
+		if currentCharset != shiftedCharset {
+			currentCharset = shiftedCharset
+			continue
+		}
+
+		if ch == '\u0000' {
+			continue
+		}
+
+		str = append(str, ch)
+	}
+
+	return string(str), nil
+}
+
+// Interleave CCIR 476 signals into DX/RX positions.
+// The emission starts with phasing pairs(RQ in DX, alpha in RX) and ends with alpha signals in DX position.
+func (c *sitorB) Interleave(signals []byte) []byte {
+	dx := make([]byte, 0, phasingLenSITORB+len(signals)+endLenSITORB)
+	for i := 0; i < phasingLenSITORB; i++ {
+		dx = append(dx, RQ_CCIR476)
+	}
+	dx = append(dx, signals...)
+	for i := 0; i < endLenSITORB; i++ {
+		dx = append(dx, ALPHA_CCIR476)
+	}
+
+	emission := make([]byte, 0, 2*len(dx))
+	for i := range dx {
+		rx := ALPHA_CCIR476
+		if j := i - rxDelaySITORB - phasingLenSITORB; j >= 0 && j < len(signals) {
+			rx = signals[j]
+		}
+		emission = append(emission, dx[i], rx)
+	}
+
+	return emission
+}
+
+// Deinterleave a SITOR-B emission into ITA2 codes.
+// For every character the copy passing the 4:3 ratio check is taken, if neither copy(or both, but they differ) passes, ERASURE is emitted.
+func (c *sitorB) Deinterleave(emission []byte) ([]byte, error) {
+	offset, ok := findPhasingSITORB(emission)
+	if !ok {
+		if c.ignErr {
+			return []byte{}, nil
+		}
+		return nil, fmt.Errorf("No phasing signal found")
+	}
+
+	var dx, rx []byte
+	for i := offset; i < len(emission); i += 2 {
+		dx = append(dx, emission[i])
+		if i+1 < len(emission) {
+			rx = append(rx, emission[i+1])
+		} else {
+			rx = append(rx, 0)
+		}
+	}
+
+	var (
+		codes   []byte
+		started bool
+	)
+	for i := range dx {
+		var rxSignal byte
+		if i+rxDelaySITORB < len(rx) {
+			rxSignal = rx[i+rxDelaySITORB]
+		}
+
+		signal, ok := pickSignalSITORB(dx[i], rxSignal)
+		if !started {
+			// skip phasing, traffic starts with the first character which is not a phasing signal
+			if ok && (signal == RQ_CCIR476 || signal == ALPHA_CCIR476) {
+				continue
+			}
+			if !ok && (dx[i] == RQ_CCIR476 || rxSignal == ALPHA_CCIR476) {
+				continue
+			}
+			started = true
+		}
+
+		if ok && signal == ALPHA_CCIR476 {
+			break
+		}
+
+		code, valid := ccir476ToITA2[signal]
+		if !ok || !valid {
+			codes = append(codes, ERASURE)
+			continue
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// findPhasingSITORB returns the offset of the first DX position, DX positions carry RQ during phasing
+func findPhasingSITORB(emission []byte) (int, bool) {
+	var count [2]int
+	for i, signal := range emission {
+		if signal == RQ_CCIR476 {
+			count[i%2]++
+		}
+	}
+
+	if count[0] == 0 && count[1] == 0 {
+		return 0, false
+	}
+	if count[1] > count[0] {
+		return 1, true
+	}
+
+	return 0, true
+}
+
+func pickSignalSITORB(dx byte, rx byte) (byte, bool) {
+	dxValid, rxValid := IsValidCCIR476(dx), IsValidCCIR476(rx)
+	switch {
+	case dxValid && rxValid:
+		return dx, dx == rx
+	case dxValid:
+		return dx, true
+	case rxValid:
+		return rx, true
+	}
+
+	return 0, false
+}
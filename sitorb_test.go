@@ -0,0 +1,118 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCCIR476Table(t *testing.T) {
+	seen := map[byte]bool{}
+	for code, signal := range ita2ToCCIR476 {
+		if !IsValidCCIR476(signal) {
+			t.Errorf("signal for ITA2 code %d should have 4:3 ratio, got %#x", code, signal)
+		}
+		if seen[signal] {
+			t.Errorf("signal %#x is used more than once", signal)
+		}
+		seen[signal] = true
+	}
+
+	for _, signal := range []byte{ALPHA_CCIR476, BETA_CCIR476, RQ_CCIR476} {
+		if !IsValidCCIR476(signal) || seen[signal] {
+			t.Errorf("service signal %#x should be valid and distinct from traffic signals", signal)
+		}
+	}
+}
+
+func TestSITORBEncode(t *testing.T) {
+	c := NewSITORB(false)
+	emission, err := c.Encode("AB")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	// NULL, LS, A, B
+	traffic := []byte{0x6A, 0x5A, 0x47, 0x72}
+	if len(emission) != 2*(phasingLenSITORB+len(traffic)+endLenSITORB) {
+		t.Fatalf("unexpected emission length %d", len(emission))
+	}
+	for i, signal := range traffic {
+		dx := 2 * (phasingLenSITORB + i)
+		rx := dx + 2*rxDelaySITORB + 1
+		if emission[dx] != signal || emission[rx] != signal {
+			t.Errorf("expect %#x in DX position %d and RX position %d, got %#x and %#x", signal, dx, rx, emission[dx], emission[rx])
+		}
+	}
+}
+
+func TestSITORBDecode(t *testing.T) {
+	c := NewSITORB(false)
+	emission, _ := c.Encode("NAVTEX 123")
+	dx := 2 * (phasingLenSITORB + 2)
+	rx := dx + 2*rxDelaySITORB + 1
+
+	tt := []struct {
+		caseName   string
+		emission   func() []byte
+		expect     string
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test clean emission",
+			emission:   func() []byte { return emission },
+			expect:     "NAVTEX 123",
+			failedText: "expect 'NAVTEX 123', got %v",
+		},
+		{
+			caseName: "test mutilated DX copy",
+			emission: func() []byte {
+				e := append([]byte{}, emission...)
+				e[dx] ^= 0x01
+				return e
+			},
+			expect:     "NAVTEX 123",
+			failedText: "expect 'NAVTEX 123', got %v",
+		},
+		{
+			caseName: "test mutilated DX and RX copies",
+			emission: func() []byte {
+				e := append([]byte{}, emission...)
+				e[dx] ^= 0x01
+				e[rx] ^= 0x10
+				return e
+			},
+			expect:     "*AVTEX 123",
+			failedText: "expect '*AVTEX 123', got %v",
+		},
+		{
+			caseName: "test emission not starting on DX position",
+			emission: func() []byte {
+				return append([]byte{0x00}, emission...)
+			},
+			expect:     "NAVTEX 123",
+			failedText: "expect 'NAVTEX 123', got %v",
+		},
+		{
+			caseName: "test emission without phasing",
+			emission: func() []byte {
+				return []byte{0x47, 0x47}
+			},
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			str, err := c.Decode(tc.emission())
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", str, err))
+				}
+			} else if tc.shouldFail || tc.expect != str {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", str, err))
+			}
+		})
+	}
+}
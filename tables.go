@@ -0,0 +1,213 @@
+/*
+ * Tables of the variants. Every variant, built-in or built at runtime(e.g. national versions of ITA2), is registered under its own version
+ * with its registers, its encoding table and its shift codes, so encode/decode and everything built on them work the same way on all of them.
+ * A shift code may depend on the register it is read in, the shift codes between two registers are found by searching the shifts.
+ * Variants are identified by their name, and versions are never released, so at most 192 variants can be registered at runtime.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+//go:generate go run ./cmd/baudotgen -o murray_tables.go variants/murray.json
+
+const (
+	firstCustomVersion version = 64
+	// MaxCustomVariants is the number of variants which can be registered at runtime
+	MaxCustomVariants = 256 - int(firstCustomVersion)
+)
+
+type tables struct {
+	name string
+	// names of the registers, indexed by Charset
+	names []string
+	// decoding tables, indexed by Charset
+	registers []map[byte]rune
+	// encoding table, the code of the character in each register, -1 if the register doesn't have it
+	charmap map[rune][]int8
+	// shifts[charset] maps the shift codes read in charset to the register they shift to
+	shifts []map[byte]Charset
+	// start is sent before the shift code a sequence starts with
+	start []byte
+}
+
+var (
+	customMu      sync.RWMutex
+	customTables  = map[version]*tables{}
+	customNames   = map[string]version{}
+	nextCustomVer = firstCustomVersion
+)
+
+func init() {
+	ita2 := func(name string, letters, figures map[byte]rune, charmap map[rune][2]int8) *tables {
+		return twoRegisters(name, letters, figures, charmap, LS, FS, []byte{NULL})
+	}
+
+	customTables[versionITA1] = twoRegisters("ITA1", lettersITA1, figuresITA1, charmapITA1, LS_ITA1, FS_ITA1, nil)
+	customTables[versionITA1Continental] = twoRegisters("ITA1 continental", lettersITA1Continental, figuresITA1Continental, charmapITA1Continental, LS_ITA1, FS_ITA1, nil)
+	customTables[versionITA2] = ita2("ITA2", lettersITA2, figuresITA2, charmapITA2)
+	customTables[versionUSTTY] = ita2("US TTY", lettersITA2, figuresUSTTY, charmapUSTTY)
+	customTables[versionMurray] = murrayTables()
+	customTables[versionWeather] = ita2("US weather", lettersITA2, figuresWeather, charmapWeather)
+	customTables[versionKatakana] = katakanaTables()
+	for ver, t := range customTables {
+		customNames[t.name] = ver
+	}
+}
+
+// twoRegisters builds the tables of a Letters/Figures variant
+func twoRegisters(name string, letters, figures map[byte]rune, charmap map[rune][2]int8, ls, fs byte, start []byte) *tables {
+	t := &tables{
+		name:      name,
+		names:     []string{"letters", "figures"},
+		registers: []map[byte]rune{letters, figures},
+		charmap:   map[rune][]int8{},
+		start:     start,
+	}
+	for char, values := range charmap {
+		t.charmap[char] = []int8{values[Letters], values[Figures]}
+	}
+	for range t.registers {
+		t.shifts = append(t.shifts, map[byte]Charset{ls: Letters, fs: Figures})
+	}
+
+	return t
+}
+
+// registerTables verifies tables and registers them under a new version.
+// Registering tables with the name of a variant again returns its version if the tables are the same, and an error otherwise.
+func registerTables(t *tables) (version, error) {
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	if ver, ok := customNames[t.name]; ok {
+		if !reflect.DeepEqual(customTables[ver].definition(), t.definition()) {
+			return 0, fmt.Errorf("Duplicate Variant: %s", t.name)
+		}
+		return ver, nil
+	}
+	if err := t.verify(); err != nil {
+		return 0, err
+	}
+	if nextCustomVer == 0 {
+		return 0, fmt.Errorf("Too many variants: %d registered", MaxCustomVariants)
+	}
+
+	ver := nextCustomVer
+	nextCustomVer++
+	customTables[ver] = t
+	customNames[t.name] = ver
+
+	return ver, nil
+}
+
+func lookupTables(ver version) (*tables, error) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	t, ok := customTables[ver]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported version: %d", ver)
+	}
+
+	return t, nil
+}
+
+// charmapOf builds the encoding table from the decoding tables
+func charmapOf(registers []map[byte]rune) map[rune][]int8 {
+	charmap := map[rune][]int8{}
+	for charset, register := range registers {
+		for code, char := range register {
+			values, ok := charmap[char]
+			if !ok {
+				values = make([]int8, len(registers))
+				for i := range values {
+					values[i] = -1
+				}
+			}
+			if values[charset] == -1 || int8(code) < values[charset] {
+				values[charset] = int8(code)
+			}
+			charmap[char] = values
+		}
+	}
+
+	return charmap
+}
+
+// shiftPath returns the shortest sequence of shift codes taking the receiver from one register to another
+func shiftPath(ver version, from Charset, to Charset) ([]byte, error) {
+	t, err := lookupTables(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.shiftPath(from, to)
+}
+
+func (t *tables) shiftPath(from Charset, to Charset) ([]byte, error) {
+	paths := map[Charset][]byte{from: {}}
+	queue := []Charset{from}
+	for len(queue) > 0 {
+		charset := queue[0]
+		queue = queue[1:]
+		if charset == to {
+			return paths[charset], nil
+		}
+
+		// visit the codes in order so the result doesn't depend on map iteration
+		for code := 0; code < 32; code++ {
+			next, ok := t.shifts[charset][byte(code)]
+			if _, seen := paths[next]; !ok || seen {
+				continue
+			}
+			paths[next] = append(append([]byte{}, paths[charset]...), byte(code))
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, fmt.Errorf("Unreachable register: %d", to)
+}
+
+// shiftCode returns the code shifting to charset from every register
+func shiftCode(ver version, charset Charset) (byte, error) {
+	t, err := lookupTables(ver)
+	if err != nil {
+		return 0, err
+	}
+
+	for code := 0; code < 32; code++ {
+		absolute := true
+		for _, shifts := range t.shifts {
+			if to, ok := shifts[byte(code)]; !ok || to != charset {
+				absolute = false
+				break
+			}
+		}
+		if absolute {
+			return byte(code), nil
+		}
+	}
+
+	return 0, fmt.Errorf("No shift code to register: %d", charset)
+}
+
+// startCodes returns the codes a sequence starting in charset begins with, the receiver is expected to start in Letters
+func startCodes(ver version, charset Charset) []byte {
+	t, err := lookupTables(ver)
+	if err != nil {
+		return []byte{NULL, LS}
+	}
+
+	codes := append([]byte{}, t.start...)
+	if code, err := shiftCode(ver, charset); err == nil {
+		return append(codes, code)
+	}
+	path, _ := t.shiftPath(Letters, charset)
+
+	return append(codes, path...)
+}
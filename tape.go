@@ -0,0 +1,92 @@
+/*
+ * Punched paper tape rendering. Each code is a row across the tape: holes 1 and 2, the smaller feed hole, holes 3 to 5.
+ * Hole 1 is the first bit on the line, the least significant bit of the code, so E(code 1) punches hole 1 only.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// column of each hole across the tape, the feed hole is column 2
+var tapeColumns = [5]int{0, 1, 3, 4, 5}
+
+const tapeFeedColumn = 2
+
+// TapeText renders codes as punched tape, one line per code, 'o' for a hole and '.' for the feed hole
+func TapeText(codes []byte) (string, error) {
+	var b strings.Builder
+	for i, code := range codes {
+		if code >= 32 {
+			return "", &CodecError{Kind: InvalidCode, Code: code, Position: i}
+		}
+
+		row := []byte("      ")
+		row[tapeFeedColumn] = '.'
+		for hole, column := range tapeColumns {
+			if code&(1<<hole) != 0 {
+				row[column] = 'o'
+			}
+		}
+		b.WriteString("|" + string(row) + "|\n")
+	}
+
+	return b.String(), nil
+}
+
+// WriteTapePNG renders codes as a PNG image of punched tape, scale is the size of a row in pixels
+func WriteTapePNG(w io.Writer, codes []byte, scale int) error {
+	if scale < 4 {
+		return fmt.Errorf("Invalid scale: %d", scale)
+	}
+
+	tape := color.Gray{Y: 0xE8}
+	hole := color.Gray{Y: 0x20}
+	// half a row of leader before and after the codes, a column of margin on both edges
+	img := image.NewGray(image.Rect(0, 0, tapePNGWidth(scale), (len(codes)+1)*scale))
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			img.SetGray(x, y, tape)
+		}
+	}
+
+	punch := func(row int, column int, radius float64) {
+		cx, cy := float64(column+1)*float64(scale)+float64(scale)/2, float64(row)*float64(scale)+float64(scale)
+		for y := int(cy - radius); y <= int(cy+radius); y++ {
+			for x := int(cx - radius); x <= int(cx+radius); x++ {
+				dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+				if dx*dx+dy*dy <= radius*radius {
+					img.SetGray(x, y, hole)
+				}
+			}
+		}
+	}
+	for row, code := range codes {
+		if code >= 32 {
+			return &CodecError{Kind: InvalidCode, Code: code, Position: row}
+		}
+		punch(row, tapeFeedColumn, float64(scale)/6)
+		for bit, column := range tapeColumns {
+			if code&(1<<bit) != 0 {
+				punch(row, column, float64(scale)*0.35)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+func tapePNGWidth(scale int) int {
+	return (len(tapeColumns) + 3) * scale
+}
+
+// tapePNGPixels returns the size of the image WriteTapePNG renders for n codes
+func tapePNGPixels(n int, scale int) int {
+	return tapePNGWidth(scale) * (n + 1) * scale
+}
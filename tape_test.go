@@ -0,0 +1,57 @@
+package baudot
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestTapeText(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		codes      []byte
+		expect     string
+		shouldFail bool
+	}{
+		{caseName: "test blank", codes: []byte{0}, expect: "|  .   |\n"},
+		{caseName: "test E", codes: []byte{1}, expect: "|o .   |\n"},
+		{caseName: "test LTRS FIGS", codes: []byte{31, 27}, expect: "|oo.ooo|\n|oo. oo|\n"},
+		{caseName: "test invalid code", codes: []byte{32}, shouldFail: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tape, err := TapeText(tc.codes)
+			if (err != nil) != tc.shouldFail || tape != tc.expect {
+				t.Errorf("expect %q, got %q, %v", tc.expect, tape, err)
+			}
+		})
+	}
+}
+
+func TestWriteTapePNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTapePNG(&buf, []byte{31, 0}, 10); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 80 || size.Y != 30 {
+		t.Fatalf("expect 80x30, got %v", size)
+	}
+
+	dark := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r < 0x8000
+	}
+	// hole 1 of the first row, the same position on the second row and the feed hole of the second row
+	if !dark(15, 10) || dark(15, 20) || !dark(35, 20) {
+		t.Errorf("expect holes punched for LTRS only")
+	}
+
+	if err := WriteTapePNG(&buf, []byte{1}, 2); err == nil {
+		t.Errorf("expect an error for a tiny scale")
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ * Text telephones for the deaf(TDD/TTY) as specified by TIA-825A: US TTY code at 45.45 baud, 1400 Hz mark and 1800 Hz space tones.
+ * TDDs are half duplex: a TDD can't receive while it transmits, so parties take turns and end each turn with GA(go ahead), SK ends the call.
+ * The transmitter holds the mark tone for a while after the last character so the carrier doesn't drop between characters,
+ * the receiver unshifts on space(USOS) and returns to letters when the carrier drops, so the transmitter starts every transmission with a shift code
+ * and sends FIGS again after a space before figures.
+ */
+
+package baudot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TDDBaud       = 45.45
+	TDDMarkHz     = 1400.0
+	TDDSpaceHz    = 1800.0
+	TDDStopBits   = 1.5
+	TDDSampleRate = 8000
+	// TDDCarrierHold is how long the mark tone is held after the last character of a transmission
+	TDDCarrierHold = 150 * time.Millisecond
+)
+
+type Turn byte
+
+const (
+	// nobody has sent GA yet
+	TurnOpen Turn = 0
+	// the local party is typing or has been given the turn
+	TurnLocal Turn = 1
+	// the remote party is typing or has been given the turn
+	TurnRemote Turn = 2
+	// a party has sent SK
+	TurnEnded Turn = 3
+)
+
+// TDDProfile returns the FSK options of TIA-825A at sampleRate
+func TDDProfile(sampleRate int) FSKOptions {
+	return FSKOptions{
+		SampleRate: sampleRate,
+		Baud:       TDDBaud,
+		MarkHz:     TDDMarkHz,
+		SpaceHz:    TDDSpaceHz,
+		StopBits:   TDDStopBits,
+	}
+}
+
+type TDDSessionOptions struct {
+	// SampleRate of the audio, 0 uses TDDSampleRate
+	SampleRate int
+	// CarrierHold after the last character, 0 uses TDDCarrierHold
+	CarrierHold time.Duration
+}
+
+type tddSession struct {
+	modulator   *fskModulator
+	demodulator *fskDemodulator
+	carrierHold time.Duration
+	// register of the receiver
+	charset Charset
+	carrier bool
+	// text received in the current remote turn
+	remoteText []rune
+	turn       Turn
+}
+
+// NewTDDSession creates one end of a TDD conversation, it turns typed text into audio and received audio into text
+func NewTDDSession(options TDDSessionOptions) (*tddSession, error) {
+	if options.SampleRate == 0 {
+		options.SampleRate = TDDSampleRate
+	}
+	if options.CarrierHold == 0 {
+		options.CarrierHold = TDDCarrierHold
+	}
+
+	modulator, err := NewFSKModulator(TDDProfile(options.SampleRate))
+	if err != nil {
+		return nil, err
+	}
+	demodulator, err := NewFSKDemodulator(TDDProfile(options.SampleRate))
+	if err != nil {
+		return nil, err
+	}
+
+	return &tddSession{
+		modulator:   modulator,
+		demodulator: demodulator,
+		carrierHold: options.CarrierHold,
+	}, nil
+}
+
+// Turn returns whose turn it is
+func (s *tddSession) Turn() Turn {
+	return s.turn
+}
+
+// Carrier reports whether the remote party is transmitting
+func (s *tddSession) Carrier() bool {
+	return s.carrier
+}
+
+// Send returns the audio of a transmission of text, it fails while the remote party is transmitting
+func (s *tddSession) Send(text string) ([]float64, error) {
+	if s.turn == TurnEnded {
+		return nil, fmt.Errorf("Call ended")
+	}
+	if s.carrier {
+		return nil, fmt.Errorf("Line busy: remote party is transmitting")
+	}
+
+	codes, err := EncodeTDD(text)
+	if err != nil {
+		return nil, err
+	}
+
+	s.turn = turnAfter(text, TurnLocal, TurnRemote)
+	samples := s.modulator.Modulate(codes)
+
+	return append(samples, s.modulator.Mark(s.carrierHold)...), nil
+}
+
+// Receive demodulates audio from the line and returns the text received
+func (s *tddSession) Receive(samples []float64) (string, error) {
+	var str []rune
+	for i := range samples {
+		codes := s.demodulator.Feed(samples[i : i+1])
+
+		if carrier := s.demodulator.Carrier(); carrier != s.carrier {
+			s.carrier = carrier
+			if carrier {
+				if s.turn != TurnRemote {
+					s.remoteText = nil
+				}
+				if s.turn != TurnEnded {
+					s.turn = TurnRemote
+				}
+			} else {
+				s.charset = Letters
+				if s.turn == TurnRemote {
+					s.turn = turnAfter(string(s.remoteText), TurnRemote, TurnLocal)
+				}
+			}
+		}
+
+		for _, code := range codes {
+			ch, shiftedCharset, err := decodeChar(code, s.charset, versionUSTTY)
+			if err != nil {
+				return string(str), err
+			}
+			if shiftedCharset != s.charset {
+				s.charset = shiftedCharset
+				continue
+			}
+			if ch == ' ' {
+				s.charset = Letters
+			}
+			if ch != '\u0000' {
+				str = append(str, ch)
+				s.remoteText = append(s.remoteText, ch)
+			}
+		}
+	}
+
+	return string(str), nil
+}
+
+// EncodeTDD encodes text for a TDD receiver: upper case, starting with a shift code and with FIGS sent again after spaces
+func EncodeTDD(text string) ([]byte, error) {
+	codes, err := encodeOptimized(strings.ToUpper(text), OptimizeOptions{UnshiftOnSpace: true}, versionUSTTY)
+	if err != nil {
+		return nil, err
+	}
+
+	// the receiver is in letters after the carrier drops, the leading NULL isn't needed
+	return codes[len(startCodes(versionUSTTY, Letters))-1:], nil
+}
+
+// turnAfter returns the turn after text: ended on SK, given to next on GA, current otherwise
+func turnAfter(text string, current Turn, next Turn) Turn {
+	words := strings.Fields(strings.ToUpper(text))
+	if len(words) == 0 {
+		return current
+	}
+
+	switch words[len(words)-1] {
+	case "SK", "SKSK":
+		return TurnEnded
+	case "GA":
+		return next
+	}
+
+	return current
+}
@@ -0,0 +1,152 @@
+package baudot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestEncodeTDD(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		msg        string
+		expect     []byte
+		failedText string
+	}{
+		{
+			caseName:   "test letters",
+			msg:        "hi ga",
+			expect:     []byte{31, 20, 6, 4, 26, 3},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{31, 20, 6, 4, 26, 3}),
+		},
+		{
+			caseName:   "test figures sent again after space",
+			msg:        "555 1212",
+			expect:     []byte{27, 16, 16, 16, 4, 27, 23, 19, 23, 19},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{27, 16, 16, 16, 4, 27, 23, 19, 23, 19}),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			codes, err := EncodeTDD(tc.msg)
+			if err != nil || string(codes) != string(tc.expect) {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+			}
+		})
+	}
+}
+
+func TestFSKRoundTrip(t *testing.T) {
+	options := TDDProfile(TDDSampleRate)
+	modulator, _ := NewFSKModulator(options)
+	demodulator, _ := NewFSKDemodulator(options)
+
+	codes := make([]byte, 32)
+	for i := range codes {
+		codes[i] = byte(i)
+	}
+	samples := append(modulator.Silence(100*time.Millisecond), modulator.Mark(50*time.Millisecond)...)
+	samples = append(samples, modulator.Modulate(codes)...)
+	samples = append(samples, modulator.Mark(50*time.Millisecond)...)
+
+	// noise and blocks of odd sizes
+	r := rand.New(rand.NewSource(1))
+	var got []byte
+	for i := 0; i < len(samples); i += 37 {
+		block := samples[i:int(math.Min(float64(i+37), float64(len(samples))))]
+		for j := range block {
+			block[j] += r.NormFloat64() * 0.1
+		}
+		got = append(got, demodulator.Feed(block)...)
+	}
+
+	if string(got) != string(codes) || demodulator.FramingErrors() != 0 {
+		t.Errorf("expect %v, got %v with %d framing errors", codes, got, demodulator.FramingErrors())
+	}
+	if !demodulator.Carrier() {
+		t.Errorf("expect carrier")
+	}
+}
+
+func TestTDDSession(t *testing.T) {
+	caller, _ := NewTDDSession(TDDSessionOptions{})
+	callee, _ := NewTDDSession(TDDSessionOptions{})
+	silence := make([]float64, TDDSampleRate/2)
+
+	samples, err := caller.Send("HELLO MY NUMBER IS 555 1212 GA")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if caller.Turn() != TurnRemote {
+		t.Errorf("expect remote turn for caller, got %v", caller.Turn())
+	}
+
+	// half the transmission, the callee can't interrupt
+	text, _ := callee.Receive(samples[:len(samples)/2])
+	if _, err := callee.Send("HI"); err == nil {
+		t.Errorf("expect busy error")
+	}
+	more, err := callee.Receive(append(samples[len(samples)/2:], silence...))
+	if text+more != "HELLO MY NUMBER IS 555 1212 GA" || err != nil {
+		t.Errorf("expect 'HELLO MY NUMBER IS 555 1212 GA', got %q, %v", text+more, err)
+	}
+	if callee.Turn() != TurnLocal || callee.Carrier() {
+		t.Errorf("expect local turn without carrier for callee, got %v, %v", callee.Turn(), callee.Carrier())
+	}
+
+	samples, err = callee.Send("OK 73 SK")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if text, _ := caller.Receive(append(samples, silence...)); text != "OK 73 SK" {
+		t.Errorf("expect 'OK 73 SK', got %q", text)
+	}
+	if caller.Turn() != TurnEnded || callee.Turn() != TurnEnded {
+		t.Errorf("expect ended call, got %v and %v", caller.Turn(), callee.Turn())
+	}
+	if _, err := caller.Send("HELLO"); err == nil {
+		t.Errorf("expect error after SK")
+	}
+}
+
+func TestTDDRecording(t *testing.T) {
+	sender, _ := NewTDDSession(TDDSessionOptions{})
+	samples, _ := sender.Send("RECORDED 1 2 3 GA")
+
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, samples, TDDSampleRate); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	recorded, rate, err := ReadWAV(&buf)
+	if err != nil || rate != TDDSampleRate || len(recorded) != len(samples) {
+		t.Fatalf("expect %d samples at %d Hz, got %d at %d, %v", len(samples), TDDSampleRate, len(recorded), rate, err)
+	}
+
+	receiver, _ := NewTDDSession(TDDSessionOptions{SampleRate: rate})
+	if text, err := receiver.Receive(recorded); text != "RECORDED 1 2 3 GA" || err != nil {
+		t.Errorf("expect 'RECORDED 1 2 3 GA', got %q, %v", text, err)
+	}
+}
+
+func TestReadWAVErrors(t *testing.T) {
+	if _, _, err := ReadWAV(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI "))); err == nil {
+		t.Errorf("expect error for a non WAVE file")
+	}
+	if _, _, err := ReadWAV(bytes.NewReader(nil)); err == nil {
+		t.Errorf("expect error for an empty file")
+	}
+
+	// a header claiming 4GiB of samples in a 44 bytes file
+	var buf bytes.Buffer
+	WriteWAV(&buf, nil, TDDSampleRate)
+	header := buf.Bytes()
+	binary.LittleEndian.PutUint32(header[40:], 0xFFFFFFFE)
+	if _, _, err := ReadWAV(bytes.NewReader(header)); err == nil {
+		t.Errorf("expect error for a truncated data chunk")
+	}
+}
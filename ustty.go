@@ -27,3 +27,27 @@ func (c *ustty) DecodeChar(code byte, currentCharset Charset) (rune, bool, error
 
 	return char, currentCharset != shiftedCharset, err
 }
+
+func (c *ustty) ver() version {
+	return versionUSTTY
+}
+
+// Repertoire returns the characters of a register in code order
+func (c *ustty) Repertoire(charset Charset) []rune {
+	return repertoire(versionUSTTY, charset)
+}
+
+// CanEncode reports whether every character of msg is in US TTY code
+func (c *ustty) CanEncode(msg string) bool {
+	return len(c.Unsupported(msg)) == 0
+}
+
+// Unsupported returns the characters of msg which are not in US TTY code
+func (c *ustty) Unsupported(msg string) []UnsupportedChar {
+	return unsupported(msg, versionUSTTY, identity)
+}
+
+// EncodedLen returns the number of codes Encode returns for msg, shift codes included
+func (c *ustty) EncodedLen(msg string) (int, error) {
+	return encodedLen(msg, c.ignErr, versionUSTTY)
+}
@@ -0,0 +1,109 @@
+/*
+ * Table consistency verifier. The encoding table and the decoding tables of a variant are maintained separately and can drift apart,
+ * e.g. a figure entered in the letters column of the encoding table encodes to the letter on the same code.
+ * Each register must decode every character of the encoding table to itself and the encoding table must have every character of the register.
+ * Characters of the encoding table which no register decodes to are aliases(e.g. similar glyphs), they only need to encode to a character of the register.
+ * Positions holding '\u0000' are blank, several of them may share a register.
+ */
+
+package baudot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// VerifyTables checks the tables of the codec's variant, it returns every problem found
+func VerifyTables(codec Codec) error {
+	v, ok := codec.(versioned)
+	if !ok {
+		return fmt.Errorf("Unsupported codec: %T", codec)
+	}
+	t, err := lookupTables(v.ver())
+	if err != nil {
+		return err
+	}
+
+	return t.verify()
+}
+
+func (t *tables) verify() error {
+	var errs []error
+	if len(t.shifts) != len(t.registers) {
+		return fmt.Errorf("Invalid tables: %d registers, %d shift tables", len(t.registers), len(t.shifts))
+	}
+
+	decoded := map[rune]bool{}
+	for charset, register := range t.registers {
+		codes := make([]int, 0, len(register))
+		for code := range register {
+			codes = append(codes, int(code))
+		}
+		// report in order so the result doesn't depend on map iteration
+		sort.Ints(codes)
+
+		seen := map[rune]byte{}
+		for _, c := range codes {
+			code := byte(c)
+			char := register[code]
+			decoded[char] = true
+
+			if code >= 32 {
+				errs = append(errs, fmt.Errorf("Invalid Code: %d in register %d", code, charset))
+				continue
+			}
+			if _, ok := t.shifts[charset][code]; ok {
+				errs = append(errs, fmt.Errorf("Unreachable Code: %d in register %d is a shift code", code, charset))
+				continue
+			}
+			if char == '\u0000' {
+				continue
+			}
+			if other, ok := seen[char]; ok {
+				errs = append(errs, fmt.Errorf("Ambiguous Char: %c on %d and %d in register %d", char, other, code, charset))
+				continue
+			}
+			seen[char] = code
+
+			values, ok := t.charmap[char]
+			if !ok || len(values) <= charset || values[charset] == -1 {
+				errs = append(errs, fmt.Errorf("Missing Char: %c on %d in register %d is not in the encoding table", char, code, charset))
+			}
+		}
+	}
+
+	chars := make([]rune, 0, len(t.charmap))
+	for char := range t.charmap {
+		chars = append(chars, char)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+
+	for _, char := range chars {
+		values := t.charmap[char]
+		if len(values) != len(t.registers) {
+			errs = append(errs, fmt.Errorf("Invalid Char: %c has codes for %d registers", char, len(values)))
+			continue
+		}
+		for charset, code := range values {
+			if code == -1 {
+				continue
+			}
+			got, ok := t.registers[charset][byte(code)]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("Invalid Char: %c encodes to %d in register %d which has no such code", char, code, charset))
+			case got != char && (decoded[char] || got == '\u0000'):
+				errs = append(errs, fmt.Errorf("Invalid Char: %c encodes to %d in register %d which decodes to %q", char, code, charset, got))
+			}
+		}
+	}
+
+	for charset := range t.registers {
+		if _, err := t.shiftPath(Letters, Charset(charset)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
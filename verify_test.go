@@ -0,0 +1,66 @@
+package baudot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyTables(t *testing.T) {
+	codecs := []Codec{NewITA1(false), NewITA1Continental(false), NewITA2(false), NewUSTTY(false), NewMurray(false), NewWeather(false), NewKatakana(false)}
+	for _, profile := range NationalProfiles() {
+		c, err := NewITA2National(profile, false)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		codecs = append(codecs, c)
+	}
+
+	for _, c := range codecs {
+		if err := VerifyTables(c); err != nil {
+			t.Errorf("expect consistent tables for %T(%d), got %v", c, c.(versioned).ver(), err)
+		}
+	}
+}
+
+func TestVerifyBrokenTables(t *testing.T) {
+	letters := map[byte]rune{1: 'A', 2: 'B', 3: 'A', 31: 'X', 40: 'Y'}
+	figures := map[byte]rune{1: '1', 2: '2', 4: '\u0000', 5: '\u0000'}
+	tt := []struct {
+		caseName string
+		charmap  map[rune][2]int8
+		expect   []string
+	}{
+		{
+			caseName: "test figure in letters column",
+			charmap:  map[rune][2]int8{'A': {1, -1}, 'B': {2, -1}, '1': {1, -1}, '2': {-1, 2}},
+			expect:   []string{"Missing Char: 1 on 1 in register 1", "Invalid Char: 1 encodes to 1 in register 0"},
+		},
+		{
+			caseName: "test ambiguous and unreachable codes",
+			charmap:  map[rune][2]int8{'A': {1, -1}, 'B': {2, -1}, '1': {-1, 1}, '2': {-1, 2}},
+			expect:   []string{"Ambiguous Char: A on 1 and 3", "Unreachable Code: 31", "Invalid Code: 40"},
+		},
+		{
+			caseName: "test alias",
+			charmap:  map[rune][2]int8{'A': {1, -1}, 'B': {2, -1}, '1': {-1, 1}, '2': {-1, 2}, 'Ⅰ': {-1, 1}, '½': {-1, 4}, '¼': {-1, 6}},
+			expect:   []string{"Invalid Char: ½ encodes to 4 in register 1 which decodes to '\\x00'", "Invalid Char: ¼ encodes to 6 in register 1 which has no such code"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			err := twoRegisters("broken", letters, figures, tc.charmap, LS, FS, nil).verify()
+			if err == nil {
+				t.Fatalf("expect errors %v, got nil", tc.expect)
+			}
+			for _, expect := range tc.expect {
+				if !strings.Contains(err.Error(), expect) {
+					t.Errorf("expect %q in %v", expect, err)
+				}
+			}
+			if strings.Contains(err.Error(), "Ⅰ") {
+				t.Errorf("expect alias accepted, got %v", err)
+			}
+		})
+	}
+}
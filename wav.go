@@ -0,0 +1,128 @@
+/*
+ * WAV files of mono 16 bit PCM audio, enough to store and replay the output of the modulators.
+ * Samples are floats between -1 and 1.
+ */
+
+package baudot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WriteWAV writes samples as a mono 16 bit PCM WAV file
+func WriteWAV(w io.Writer, samples []float64, sampleRate int) error {
+	if sampleRate <= 0 {
+		return fmt.Errorf("Invalid sample rate: %d", sampleRate)
+	}
+
+	dataLen := uint32(len(samples) * 2)
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataLen)
+	buf.WriteString("WAVEfmt ")
+	for _, field := range []interface{}{
+		uint32(16),             // fmt chunk size
+		uint16(1),              // PCM
+		uint16(1),              // mono
+		uint32(sampleRate),     // sample rate
+		uint32(sampleRate * 2), // byte rate
+		uint16(2),              // block align
+		uint16(16),             // bits per sample
+	} {
+		binary.Write(&buf, binary.LittleEndian, field)
+	}
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataLen)
+	for _, sample := range samples {
+		sample = math.Max(-1, math.Min(1, sample))
+		binary.Write(&buf, binary.LittleEndian, int16(math.Round(sample*math.MaxInt16)))
+	}
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+// ReadWAV reads a 16 bit PCM WAV file, the channels of a stereo file are mixed.
+// Returns the samples and the sample rate
+func ReadWAV(r io.Reader) ([]float64, int, error) {
+	var header struct {
+		RIFF [4]byte
+		Size uint32
+		WAVE [4]byte
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, 0, fmt.Errorf("Invalid WAV: %v", err)
+	}
+	if string(header.RIFF[:]) != "RIFF" || string(header.WAVE[:]) != "WAVE" {
+		return nil, 0, fmt.Errorf("Invalid WAV: not a RIFF WAVE file")
+	}
+
+	var format struct {
+		AudioFormat   uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+	}
+	hasFormat := false
+	for {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			return nil, 0, fmt.Errorf("Invalid WAV: %v", err)
+		}
+
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			if chunk.Size < 16 {
+				return nil, 0, fmt.Errorf("Invalid WAV: fmt chunk of %d bytes", chunk.Size)
+			}
+			if err := binary.Read(r, binary.LittleEndian, &format); err != nil {
+				return nil, 0, fmt.Errorf("Invalid WAV: %v", err)
+			}
+			if _, err := io.CopyN(io.Discard, r, int64(chunk.Size-16+chunk.Size%2)); err != nil {
+				return nil, 0, fmt.Errorf("Invalid WAV: %v", err)
+			}
+			if format.AudioFormat != 1 || format.BitsPerSample != 16 || format.Channels == 0 {
+				return nil, 0, fmt.Errorf("Unsupported WAV: format %d, %d bits, %d channels", format.AudioFormat, format.BitsPerSample, format.Channels)
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return nil, 0, fmt.Errorf("Invalid WAV: data before fmt chunk")
+			}
+			// the size in the header is not trusted, the buffer only grows with the bytes actually read
+			data, err := io.ReadAll(io.LimitReader(r, int64(chunk.Size)))
+			if err != nil {
+				return nil, 0, fmt.Errorf("Invalid WAV: %v", err)
+			}
+			if len(data) < int(chunk.Size) {
+				return nil, 0, fmt.Errorf("Invalid WAV: %v", io.ErrUnexpectedEOF)
+			}
+
+			channels := int(format.Channels)
+			samples := make([]float64, len(data)/2/channels)
+			for i := range samples {
+				for c := 0; c < channels; c++ {
+					sample := int16(binary.LittleEndian.Uint16(data[(i*channels+c)*2:]))
+					samples[i] += float64(sample) / math.MaxInt16
+				}
+				samples[i] /= float64(channels)
+			}
+
+			return samples, int(format.SampleRate), nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(chunk.Size+chunk.Size%2)); err != nil {
+				return nil, 0, fmt.Errorf("Invalid WAV: %v", err)
+			}
+		}
+	}
+}
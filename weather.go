@@ -0,0 +1,55 @@
+/*
+ * US weather teleprinter code is a variant of US TTY(ITA2) used on weather networks,
+ * the figures register carries wind direction arrows and sky cover symbols in place of most punctuation.
+ * Symbols decode to the closest Unicode glyph, similar glyphs(e.g. '⬆' for '↑') are accepted when encoding.
+ */
+
+package baudot
+
+// Encode string into byte array represent the sequence of US weather code
+func (c *weather) Encode(msg string) ([]byte, error) {
+	return encode(msg, c.ignErr, versionWeather)
+}
+
+// Decode US weather code to string
+func (c *weather) Decode(codes []byte) (string, error) {
+	return decode(codes, c.ignErr, versionWeather)
+}
+
+// EncodeChar encodes a character into US weather code
+func (c *weather) EncodeChar(char rune, currentCharset Charset) (byte, bool, error) {
+	code, shiftedCharset, err := encodeChar(char, currentCharset, versionWeather)
+
+	return code, shiftedCharset != currentCharset, err
+}
+
+// DecodeChar decodes a US weather code to rune
+func (c *weather) DecodeChar(code byte, currentCharset Charset) (rune, bool, error) {
+	char, shiftedCharset, err := decodeChar(code, currentCharset, versionWeather)
+
+	return char, currentCharset != shiftedCharset, err
+}
+
+func (c *weather) ver() version {
+	return versionWeather
+}
+
+// Repertoire returns the characters of a register in code order
+func (c *weather) Repertoire(charset Charset) []rune {
+	return repertoire(versionWeather, charset)
+}
+
+// CanEncode reports whether every character of msg is in US weather code
+func (c *weather) CanEncode(msg string) bool {
+	return len(c.Unsupported(msg)) == 0
+}
+
+// Unsupported returns the characters of msg which are not in US weather code
+func (c *weather) Unsupported(msg string) []UnsupportedChar {
+	return unsupported(msg, versionWeather, identity)
+}
+
+// EncodedLen returns the number of codes Encode returns for msg, shift codes included
+func (c *weather) EncodedLen(msg string) (int, error) {
+	return encodedLen(msg, c.ignErr, versionWeather)
+}
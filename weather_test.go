@@ -0,0 +1,63 @@
+package baudot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWeatherEncode(t *testing.T) {
+	tt := []struct {
+		caseName   string
+		msg        string
+		expect     []byte
+		shouldFail bool
+		failedText string
+	}{
+		{
+			caseName:   "test wind and sky cover",
+			msg:        "KORD ↗15 ⊕",
+			expect:     []byte{0, 31, 15, 24, 10, 9, 4, 27, 9, 23, 16, 4, 25},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 31, 15, 24, 10, 9, 4, 27, 9, 23, 16, 4, 25}),
+		},
+		{
+			caseName:   "test similar glyphs",
+			msg:        "⬆◯",
+			expect:     []byte{0, 31, 27, 3, 14},
+			failedText: fmt.Sprintf("expect %v, got %%v", []byte{0, 31, 27, 3, 14}),
+		},
+		{
+			caseName:   "test punctuation replaced by symbols",
+			msg:        "A?",
+			shouldFail: true,
+			failedText: "expect an error, got %v",
+		},
+	}
+
+	c := NewWeather(false)
+	for _, tc := range tt {
+		t.Run(tc.caseName, func(t *testing.T) {
+			codes, err := c.Encode(tc.msg)
+			if err != nil {
+				if !tc.shouldFail {
+					t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+				}
+			} else if tc.shouldFail || string(codes) != string(tc.expect) {
+				t.Errorf(tc.failedText, fmt.Sprintf("%v, %v", codes, err))
+			}
+		})
+	}
+}
+
+func TestWeatherDecode(t *testing.T) {
+	c := NewWeather(false)
+	codes := []byte{0, 31, 15, 24, 10, 9, 4, 27, 9, 23, 16, 4, 25, 4, 14, 3, 30}
+	str, err := c.Decode(codes)
+	if err != nil || str != "KORD ↗15 ⊕ ○↑⦶" {
+		t.Errorf("expect 'KORD ↗15 ⊕ ○↑⦶', got %v, %v", str, err)
+	}
+
+	// the same codes read as US TTY
+	if str, _ := NewUSTTY(false).Decode(codes); str != "KORD $15 ? :-;" {
+		t.Errorf("expect 'KORD $15 ? :-;', got %v", str)
+	}
+}